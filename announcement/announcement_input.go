@@ -5,16 +5,53 @@ import "nurul-iman-blok-m/model"
 type AnnouncementInput struct {
 	Title       string `form:"title" binding:"required"`
 	Description string `form:"description" binding:"required"`
-	Slug        string `form:"slug" binding:"required"`
-	UserID      uint
-	User        model.User
+	Status      string `form:"status" binding:"omitempty,oneof=draft scheduled published"`
+	PublishAt   string `form:"publish_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	ExpireAt    string `form:"expire_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	// BannerMediaID reuses an image from the media library instead of uploading a banner file
+	BannerMediaID uint `form:"banner_media_id"`
+	UserID        uint
+	User          model.User
 }
 
 type AnnouncementDetailInput struct {
 	ID uint `uri:"id" binding:"required"`
 }
 
+type AnnouncementSlugInput struct {
+	Slug string `uri:"slug" binding:"required"`
+}
+
 type AnnouncementUpdateInput struct {
-	Title       string `form:"title"`
-	Description string `form:"description"`
+	Title         string `form:"title"`
+	Description   string `form:"description"`
+	Status        string `form:"status" binding:"omitempty,oneof=draft scheduled published"`
+	PublishAt     string `form:"publish_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	ExpireAt      string `form:"expire_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	BannerMediaID uint   `form:"banner_media_id"`
+	UserID        uint
+	// CanPublish is set by the handler, without it only drafts and rejected announcements can be edited
+	CanPublish bool `form:"-"`
+}
+
+type AnnouncementListInput struct {
+	Status        string `form:"status" binding:"omitempty,oneof=draft in_review rejected scheduled published expired"`
+	PublishedOnly bool   `form:"-"`
+}
+
+type AnnouncementRevisionInput struct {
+	ID       uint `uri:"id" binding:"required"`
+	Revision uint `uri:"revision" binding:"required"`
+}
+
+type AnnouncementRevisionDiffInput struct {
+	From uint `form:"from" binding:"required"`
+	To   uint `form:"to" binding:"required"`
+}
+
+// BannerInput holds the uploaded variant urls, Large is stored as the main banner
+type BannerInput struct {
+	Large     string
+	Medium    string
+	Thumbnail string
 }
@@ -1,36 +1,73 @@
 package announcement
 
-import "nurul-iman-blok-m/model"
+import (
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type BannerVariantsResponse struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
+	Large     string `json:"large"`
+}
 
 type AnnouncementFormatResponse struct {
-	ID          uint   `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Banner      string `json:"banner"`
-	Slug        string `json:"slug"`
-	CreatedBy   string `json:"created_by"`
+	ID             uint                   `json:"id"`
+	Title          string                 `json:"title"`
+	Description    string                 `json:"description"`
+	Banner         string                 `json:"banner"`
+	BannerVariants BannerVariantsResponse `json:"banner_variants"`
+	Slug           string                 `json:"slug"`
+	Status         string                 `json:"status"`
+	PublishAt      *time.Time             `json:"publish_at"`
+	ExpireAt       *time.Time             `json:"expire_at"`
+	CreatedBy      string                 `json:"created_by"`
 }
 
 func AnnouncementFormat(announcement model.Announcement, createdBy string) AnnouncementFormatResponse {
 	return AnnouncementFormatResponse{
-		ID:          announcement.ID,
-		Title:       announcement.Title,
-		Description: announcement.Description,
-		Banner:      announcement.Images,
-		Slug:        announcement.Slug,
-		CreatedBy:   createdBy,
+		ID:             announcement.ID,
+		Title:          announcement.Title,
+		Description:    announcement.Description,
+		Banner:         announcement.Images,
+		BannerVariants: bannerVariantsFormat(announcement),
+		Slug:           announcement.Slug,
+		Status:         announcement.Status,
+		PublishAt:      announcement.PublishAt,
+		ExpireAt:       announcement.ExpireAt,
+		CreatedBy:      createdBy,
 	}
 }
 
 func AnnouncementListFormat(announcement model.Announcement) AnnouncementFormatResponse {
 	return AnnouncementFormatResponse{
-		ID:          announcement.ID,
-		Title:       announcement.Title,
-		Description: announcement.Description,
-		Banner:      announcement.Images,
-		Slug:        announcement.Slug,
-		CreatedBy:   announcement.User.Name,
+		ID:             announcement.ID,
+		Title:          announcement.Title,
+		Description:    announcement.Description,
+		Banner:         announcement.Images,
+		BannerVariants: bannerVariantsFormat(announcement),
+		Slug:           announcement.Slug,
+		Status:         announcement.Status,
+		PublishAt:      announcement.PublishAt,
+		ExpireAt:       announcement.ExpireAt,
+		CreatedBy:      announcement.User.Name,
+	}
+}
+
+// bannerVariantsFormat falls back to the main banner for announcements uploaded before variants existed
+func bannerVariantsFormat(announcement model.Announcement) BannerVariantsResponse {
+	variants := BannerVariantsResponse{
+		Thumbnail: announcement.ImageThumbnail,
+		Medium:    announcement.ImageMedium,
+		Large:     announcement.Images,
+	}
+	if variants.Medium == "" {
+		variants.Medium = announcement.Images
+	}
+	if variants.Thumbnail == "" {
+		variants.Thumbnail = variants.Medium
 	}
+	return variants
 }
 
 func AnnouncementsFormat(announcements []model.Announcement) []AnnouncementFormatResponse {
@@ -43,3 +80,55 @@ func AnnouncementsFormat(announcements []model.Announcement) []AnnouncementForma
 
 	return formatter
 }
+
+type AnnouncementRevisionFormatResponse struct {
+	Revision    uint      `json:"revision"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Banner      string    `json:"banner"`
+	Slug        string    `json:"slug"`
+	EditedBy    string    `json:"edited_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RevisionDiffFormatResponse struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+func AnnouncementRevisionFormat(revision model.AnnouncementRevision) AnnouncementRevisionFormatResponse {
+	return AnnouncementRevisionFormatResponse{
+		Revision:    revision.Revision,
+		Title:       revision.Title,
+		Description: revision.Description,
+		Banner:      revision.Images,
+		Slug:        revision.Slug,
+		EditedBy:    revision.User.Name,
+		CreatedAt:   revision.CreatedAt,
+	}
+}
+
+func AnnouncementRevisionsFormat(revisions []model.AnnouncementRevision) []AnnouncementRevisionFormatResponse {
+	formatter := []AnnouncementRevisionFormatResponse{}
+
+	for _, revision := range revisions {
+		formatter = append(formatter, AnnouncementRevisionFormat(revision))
+	}
+
+	return formatter
+}
+
+func RevisionDiffFormat(diff []RevisionFieldDiff) []RevisionDiffFormatResponse {
+	formatter := []RevisionDiffFormatResponse{}
+
+	for _, field := range diff {
+		formatter = append(formatter, RevisionDiffFormatResponse{
+			Field: field.Field,
+			From:  field.From,
+			To:    field.To,
+		})
+	}
+
+	return formatter
+}
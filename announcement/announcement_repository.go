@@ -2,21 +2,28 @@ package announcement
 
 import (
 	"context"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"errors"
 	"gorm.io/gorm"
 	"nurul-iman-blok-m/model"
-	"os"
-	"strings"
+	"time"
 )
 
 type AnnouncementRepository interface {
-	AddAnnouncement(announcement model.Announcement) (model.Announcement, error)
-	GetUserName(announcement model.Announcement, userId uint) (model.Announcement, error)
-	GetListAnnouncement(list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error)
-	DetailAnnouncement(ID uint) (model.Announcement, error)
-	DeleteAnnouncement(ID uint) error
-	Update(announcement model.Announcement, s3Client s3.Client) (model.Announcement, error)
+	AddAnnouncement(ctx context.Context, announcement model.Announcement) (model.Announcement, error)
+	GetUserName(ctx context.Context, announcement model.Announcement, userId uint) (model.Announcement, error)
+	GetListAnnouncement(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error)
+	DetailAnnouncement(ctx context.Context, ID uint) (model.Announcement, error)
+	DetailAnnouncementBySlug(ctx context.Context, slug string) (model.Announcement, error)
+	DetailForUpdate(ctx context.Context, tx *gorm.DB, ID uint) (model.Announcement, error)
+	DeleteAnnouncement(ctx context.Context, ID uint) error
+	Update(ctx context.Context, announcement model.Announcement) (model.Announcement, error)
+	SaveRevision(ctx context.Context, announcement model.Announcement, userID uint) (model.AnnouncementRevision, error)
+	GetRevisions(ctx context.Context, announcementID uint) ([]model.AnnouncementRevision, error)
+	GetRevision(ctx context.Context, announcementID uint, revision uint) (model.AnnouncementRevision, error)
+	UpdateStatus(ctx context.Context, tx *gorm.DB, announcement model.Announcement) error
+	PublishDue(ctx context.Context, now time.Time) ([]model.Announcement, error)
+	CountScheduled(ctx context.Context) (int64, error)
+	ExpireDue(ctx context.Context, now time.Time) (int, error)
 }
 
 type announcementRepository struct {
@@ -27,8 +34,8 @@ func NewRepositoryAnnouncement(db *gorm.DB) *announcementRepository {
 	return &announcementRepository{db}
 }
 
-func (r *announcementRepository) AddAnnouncement(announcement model.Announcement) (model.Announcement, error) {
-	err := r.database.Create(&announcement).Error
+func (r *announcementRepository) AddAnnouncement(ctx context.Context, announcement model.Announcement) (model.Announcement, error) {
+	err := r.database.WithContext(ctx).Create(&announcement).Error
 
 	if err != nil {
 		return announcement, err
@@ -37,8 +44,8 @@ func (r *announcementRepository) AddAnnouncement(announcement model.Announcement
 	return announcement, nil
 }
 
-func (r *announcementRepository) GetUserName(announcement model.Announcement, userId uint) (model.Announcement, error) {
-	err := r.database.Preload("User").Where("id = ?", userId).Find(&announcement).Error
+func (r *announcementRepository) GetUserName(ctx context.Context, announcement model.Announcement, userId uint) (model.Announcement, error) {
+	err := r.database.WithContext(ctx).Preload("User").Where("id = ?", userId).Find(&announcement).Error
 	if err != nil {
 		return announcement, err
 	}
@@ -46,24 +53,29 @@ func (r *announcementRepository) GetUserName(announcement model.Announcement, us
 	return announcement, nil
 }
 
-func (r *announcementRepository) GetListAnnouncement(list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error) {
+func (r *announcementRepository) GetListAnnouncement(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error) {
 	var announcements []model.Announcement
 	var user model.User
 	var listAnnouncement []model.Announcement
 
-	err := r.database.Scopes(list).Find(&announcements).Error
+	err := r.database.WithContext(ctx).Scopes(filter, list).Find(&announcements).Error
 	for _, item := range announcements {
-		r.database.Where("id = ?", item.UserID).Find(&user)
+		r.database.WithContext(ctx).Where("id = ?", item.UserID).Find(&user)
 		itemAnnouncement := model.Announcement{
-			ID:          item.ID,
-			Title:       item.Title,
-			Description: item.Description,
-			Images:      item.Images,
-			User:        model.User{Name: user.Name},
-			UserID:      item.UserID,
-			Slug:        item.Slug,
-			CreatedAt:   item.CreatedAt,
-			UpdatedAt:   item.UpdatedAt,
+			ID:             item.ID,
+			Title:          item.Title,
+			Description:    item.Description,
+			Images:         item.Images,
+			ImageMedium:    item.ImageMedium,
+			ImageThumbnail: item.ImageThumbnail,
+			User:           model.User{Name: user.Name},
+			UserID:         item.UserID,
+			Slug:           item.Slug,
+			Status:         item.Status,
+			PublishAt:      item.PublishAt,
+			ExpireAt:       item.ExpireAt,
+			CreatedAt:      item.CreatedAt,
+			UpdatedAt:      item.UpdatedAt,
 		}
 		listAnnouncement = append(listAnnouncement, itemAnnouncement)
 		user = model.User{}
@@ -73,60 +85,153 @@ func (r *announcementRepository) GetListAnnouncement(list func(db *gorm.DB) *gor
 		return announcements, 0, err
 	}
 	totalCount := int64(0)
-	r.database.Find(&announcements).Count(&totalCount)
+	r.database.WithContext(ctx).Model(&model.Announcement{}).Scopes(filter).Count(&totalCount)
 	return listAnnouncement, int(totalCount), nil
 }
 
-func (r *announcementRepository) DetailAnnouncement(ID uint) (model.Announcement, error) {
+func (r *announcementRepository) DetailAnnouncement(ctx context.Context, ID uint) (model.Announcement, error) {
 	var announcement model.Announcement
-	err := r.database.Preload("User").Where("id = ?", ID).Find(&announcement).Error
+	err := r.database.WithContext(ctx).Preload("User").Where("id = ?", ID).First(&announcement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return announcement, errNotFound
+	}
 	if err != nil {
 		return announcement, err
 	}
 	return announcement, nil
 }
 
-func (r *announcementRepository) DeleteAnnouncement(ID uint) error {
+func (r *announcementRepository) DetailAnnouncementBySlug(ctx context.Context, slug string) (model.Announcement, error) {
 	var announcement model.Announcement
+	err := r.database.WithContext(ctx).Preload("User").Where("slug = ?", slug).First(&announcement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return announcement, errNotFound
+	}
+	if err != nil {
+		return announcement, err
+	}
+	return announcement, nil
+}
 
-	r.database.Where("id = ?", ID).Find(&announcement)
-	errDeleteFile := os.Remove(announcement.Images)
-	if errDeleteFile != nil {
-		return errDeleteFile
+// DetailForUpdate reads the announcement inside tx, for status changes that commit together with other rows
+func (r *announcementRepository) DetailForUpdate(ctx context.Context, tx *gorm.DB, ID uint) (model.Announcement, error) {
+	var announcement model.Announcement
+	err := tx.WithContext(ctx).Where("id = ?", ID).First(&announcement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return announcement, errNotFound
+	}
+	if err != nil {
+		return announcement, err
 	}
+	return announcement, nil
+}
 
-	err := r.database.Delete(&model.Announcement{}, ID).Error
+func (r *announcementRepository) DeleteAnnouncement(ctx context.Context, ID uint) error {
+	// soft delete, the banner is kept until the trash is purged
+	err := r.database.WithContext(ctx).Delete(&model.Announcement{}, ID).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *announcementRepository) Update(announcement model.Announcement, s3Client s3.Client) (model.Announcement, error) {
-	var currentAnnouncement model.Announcement
-	r.database.Where("id = ?", announcement.ID).Find(&currentAnnouncement)
-	if announcement.Images != currentAnnouncement.Images {
-		//errDeleteFile := os.Remove(currentAnnouncement.Images)
-		//if errDeleteFile != nil {
-		//	return announcement, errDeleteFile
-		//}
-		getPathForDelete := strings.Replace(currentAnnouncement.Images, "https://masjid-nurul-iman.s3.ap-northeast-1.amazonaws.com/", "", -1)
-		awsClient := s3Client
-
-		input := &s3.DeleteObjectInput{
-			Bucket: aws.String("masjid-nurul-iman"),
-			Key:    aws.String(getPathForDelete),
-		}
+// Update only saves the row. The previous banner is always kept by a revision, the media library
+// garbage collector deletes it once nothing refers to it anymore
+func (r *announcementRepository) Update(ctx context.Context, announcement model.Announcement) (model.Announcement, error) {
+	err := r.database.WithContext(ctx).Save(&announcement).Error
+	if err != nil {
+		return announcement, err
+	}
+	return announcement, nil
+}
 
-		_, errDeleteItem := awsClient.DeleteObject(context.TODO(), input)
+func (r *announcementRepository) SaveRevision(ctx context.Context, announcement model.Announcement, userID uint) (model.AnnouncementRevision, error) {
+	lastRevision := uint(0)
+	r.database.WithContext(ctx).Model(&model.AnnouncementRevision{}).
+		Where("announcement_id = ?", announcement.ID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&lastRevision)
+
+	revision := model.AnnouncementRevision{
+		AnnouncementID: announcement.ID,
+		Revision:       lastRevision + 1,
+		Title:          announcement.Title,
+		Description:    announcement.Description,
+		Images:         announcement.Images,
+		ImageMedium:    announcement.ImageMedium,
+		ImageThumbnail: announcement.ImageThumbnail,
+		Slug:           announcement.Slug,
+		UserID:         userID,
+	}
 
-		if errDeleteItem != nil {
-			return announcement, errDeleteItem
-		}
+	err := r.database.WithContext(ctx).Create(&revision).Error
+	if err != nil {
+		return revision, err
 	}
-	err := r.database.Save(&announcement).Error
+	return revision, nil
+}
+
+func (r *announcementRepository) GetRevisions(ctx context.Context, announcementID uint) ([]model.AnnouncementRevision, error) {
+	var revisions []model.AnnouncementRevision
+	err := r.database.WithContext(ctx).Preload("User").Where("announcement_id = ?", announcementID).Order("revision desc").Find(&revisions).Error
 	if err != nil {
-		return announcement, err
+		return revisions, err
 	}
-	return announcement, nil
+	return revisions, nil
+}
+
+func (r *announcementRepository) GetRevision(ctx context.Context, announcementID uint, revision uint) (model.AnnouncementRevision, error) {
+	var announcementRevision model.AnnouncementRevision
+	err := r.database.WithContext(ctx).Preload("User").
+		Where("announcement_id = ? AND revision = ?", announcementID, revision).
+		First(&announcementRevision).Error
+	if err != nil {
+		return announcementRevision, err
+	}
+	return announcementRevision, nil
+}
+
+func (r *announcementRepository) UpdateStatus(ctx context.Context, tx *gorm.DB, announcement model.Announcement) error {
+	err := tx.WithContext(ctx).Model(&announcement).Select("status", "publish_at").Updates(announcement).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *announcementRepository) CountScheduled(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.database.WithContext(ctx).Model(&model.Announcement{}).Where("status = ?", StatusScheduled).Count(&count).Error
+	return count, err
+}
+
+// PublishDue returns the announcements it published so they can be announced to subscribers
+func (r *announcementRepository) PublishDue(ctx context.Context, now time.Time) ([]model.Announcement, error) {
+	var announcements []model.Announcement
+	err := r.database.WithContext(ctx).Preload("User").Where("status = ? AND publish_at <= ?", StatusScheduled, now).Find(&announcements).Error
+	if err != nil || len(announcements) == 0 {
+		return announcements, err
+	}
+
+	var ids []uint
+	for i := range announcements {
+		ids = append(ids, announcements[i].ID)
+		announcements[i].Status = StatusPublished
+	}
+
+	err = r.database.WithContext(ctx).Model(&model.Announcement{}).Where("id IN ?", ids).Update("status", StatusPublished).Error
+	if err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
+
+func (r *announcementRepository) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	result := r.database.WithContext(ctx).Model(&model.Announcement{}).
+		Where("status = ? AND expire_at IS NOT NULL AND expire_at <= ?", StatusPublished, now).
+		Update("status", StatusExpired)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
 }
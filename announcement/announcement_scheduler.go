@@ -0,0 +1,20 @@
+package announcement
+
+import (
+	"context"
+	"log"
+	"nurul-iman-blok-m/worker"
+	"time"
+)
+
+// StartStatusScheduler flips scheduled and expired announcements in the background every interval
+func StartStatusScheduler(jobs *worker.Group, service AnnouncementService, interval time.Duration) {
+	jobs.Every(interval, true, func(ctx context.Context) {
+		published, expired, err := service.RefreshStatuses(ctx)
+		if err != nil {
+			log.Printf("announcement scheduler error: %v", err)
+		} else if published > 0 || expired > 0 {
+			log.Printf("announcement scheduler: %d published, %d expired", published, expired)
+		}
+	})
+}
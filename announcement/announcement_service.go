@@ -1,56 +1,143 @@
 package announcement
 
 import (
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"context"
 	"gorm.io/gorm"
+	"log"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/media"
 	"nurul-iman-blok-m/model"
-	"strings"
+	"nurul-iman-blok-m/review"
+	"nurul-iman-blok-m/slug"
+	"nurul-iman-blok-m/webhook"
+	"time"
+)
+
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusRejected  = "rejected"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusExpired   = "expired"
+)
+
+var (
+	errNotFound    = apperr.NotFound("announcement_not_found", "announcement not found")
+	errNotInReview = apperr.Conflict("announcement_not_in_review", "announcement is not in review")
+	// errNeedsPublish keeps edits to live announcements behind review, changes go live with them
+	errNeedsPublish = apperr.Forbidden("only drafts and rejected announcements can be changed without publish permission, submit for review instead")
 )
 
 type AnnouncementService interface {
-	AddAnnouncement(input AnnouncementInput, imageLocation string) (model.Announcement, string, error)
-	GetListAnnouncement(list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error)
-	GetDetailAnnouncement(input AnnouncementDetailInput) (model.Announcement, error)
-	DeleteAnnouncement(input AnnouncementDetailInput) error
-	UpdateAnnouncement(input AnnouncementDetailInput, updateData AnnouncementUpdateInput, updatePath string, s3Client s3.Client) (model.Announcement, error)
+	AddAnnouncement(ctx context.Context, input AnnouncementInput, banner BannerInput) (model.Announcement, string, error)
+	GetListAnnouncement(ctx context.Context, input AnnouncementListInput, list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error)
+	GetDetailAnnouncement(ctx context.Context, input AnnouncementDetailInput) (model.Announcement, error)
+	GetDetailAnnouncementBySlug(ctx context.Context, input AnnouncementSlugInput) (model.Announcement, error)
+	DeleteAnnouncement(ctx context.Context, input AnnouncementDetailInput, canPublish bool) error
+	UpdateAnnouncement(ctx context.Context, input AnnouncementDetailInput, updateData AnnouncementUpdateInput, banner BannerInput) (model.Announcement, error)
+	GetRevisions(ctx context.Context, input AnnouncementDetailInput) ([]model.AnnouncementRevision, error)
+	DiffRevisions(ctx context.Context, input AnnouncementDetailInput, diffInput AnnouncementRevisionDiffInput) ([]RevisionFieldDiff, error)
+	RollbackRevision(ctx context.Context, input AnnouncementRevisionInput, userID uint, canPublish bool) (model.Announcement, error)
+	RefreshStatuses(ctx context.Context) (int, int, error)
+	CountScheduled(ctx context.Context) (int64, error)
+	SubmitForReview(ctx context.Context, tx *gorm.DB, ID uint, submitter review.Submitter) (review.ReviewContent, error)
+	ApproveReview(ctx context.Context, tx *gorm.DB, ID uint) error
+	RejectReview(ctx context.Context, tx *gorm.DB, ID uint) error
+	ReviewDecided(ctx context.Context, ID uint, status string)
+}
+
+type RevisionFieldDiff struct {
+	Field string
+	From  string
+	To    string
+}
+
+// PublishNotifier is told whenever an announcement becomes visible to the public, e.g. to send push notifications
+type PublishNotifier interface {
+	AnnouncementPublished(ctx context.Context, announcement model.Announcement)
 }
 
 type announcementService struct {
-	repository AnnouncementRepository
+	repository      AnnouncementRepository
+	slugService     slug.SlugService
+	mediaService    media.MediaService
+	emitter         webhook.Emitter
+	publishNotifier PublishNotifier
 }
 
-func NewServiceAnnouncement(repository AnnouncementRepository) *announcementService {
-	return &announcementService{repository}
+func NewServiceAnnouncement(repository AnnouncementRepository, slugService slug.SlugService, mediaService media.MediaService, emitter webhook.Emitter, publishNotifier PublishNotifier) *announcementService {
+	return &announcementService{repository, slugService, mediaService, emitter, publishNotifier}
 }
 
-func (s *announcementService) AddAnnouncement(input AnnouncementInput, imageLocation string) (model.Announcement, string, error) {
+func (s *announcementService) AddAnnouncement(ctx context.Context, input AnnouncementInput, banner BannerInput) (model.Announcement, string, error) {
 	announcement := model.Announcement{}
 	announcement.Title = input.Title
 	announcement.Description = input.Description
-	announcement.Images = imageLocation
-	announcement.Slug = input.Slug
+	announcement.Images = banner.Large
+	announcement.ImageMedium = banner.Medium
+	announcement.ImageThumbnail = banner.Thumbnail
 	announcement.UserID = input.UserID
 
-	announcementCreate, err := s.repository.AddAnnouncement(announcement)
+	errStatus := applyStatus(&announcement, input.Status, input.PublishAt, input.ExpireAt)
+	if errStatus != nil {
+		return announcement, "", errStatus
+	}
 
+	var announcementCreate model.Announcement
+	err := s.slugService.SaveWithSlug(ctx, slug.TableAnnouncements, input.Title, 0, func(announcementSlug string) error {
+		announcement.Slug = announcementSlug
+		var errAdd error
+		announcementCreate, errAdd = s.repository.AddAnnouncement(ctx, announcement)
+		return errAdd
+	})
 	if err != nil {
 		return announcementCreate, "", err
 	}
-	user, _ := s.repository.GetUserName(announcement, announcement.UserID)
+
+	errRevision := s.saveRevision(ctx, announcementCreate, input.UserID)
+	if errRevision != nil {
+		return announcementCreate, "", errRevision
+	}
+	user, _ := s.repository.GetUserName(ctx, announcement, announcement.UserID)
+	announcementCreate.User = user.User
+	s.announceChange(ctx, false, announcementCreate)
 
 	return announcementCreate, user.User.Name, nil
 }
 
-func (s *announcementService) GetListAnnouncement(list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error) {
-	announcements, count, err := s.repository.GetListAnnouncement(list)
+func (s *announcementService) GetListAnnouncement(ctx context.Context, input AnnouncementListInput, list func(db *gorm.DB) *gorm.DB) ([]model.Announcement, int, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		if input.PublishedOnly {
+			now := time.Now()
+			db = db.Where("status = ?", StatusPublished).
+				Where("publish_at IS NULL OR publish_at <= ?", now).
+				Where("expire_at IS NULL OR expire_at > ?", now)
+		}
+		if input.Status != "" {
+			db = db.Where("status = ?", input.Status)
+		}
+		return db
+	}
+
+	announcements, count, err := s.repository.GetListAnnouncement(ctx, filter, list)
 	if err != nil {
 		return announcements, 0, err
 	}
 	return announcements, count, err
 }
 
-func (s *announcementService) GetDetailAnnouncement(input AnnouncementDetailInput) (model.Announcement, error) {
-	data, err := s.repository.DetailAnnouncement(input.ID)
+func (s *announcementService) GetDetailAnnouncement(ctx context.Context, input AnnouncementDetailInput) (model.Announcement, error) {
+	data, err := s.repository.DetailAnnouncement(ctx, input.ID)
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+func (s *announcementService) GetDetailAnnouncementBySlug(ctx context.Context, input AnnouncementSlugInput) (model.Announcement, error) {
+	data, err := s.repository.DetailAnnouncementBySlug(ctx, input.Slug)
 	if err != nil {
 		return data, err
 	}
@@ -58,40 +145,370 @@ func (s *announcementService) GetDetailAnnouncement(input AnnouncementDetailInpu
 	return data, nil
 }
 
-func (s *announcementService) DeleteAnnouncement(input AnnouncementDetailInput) error {
-	err := s.repository.DeleteAnnouncement(input.ID)
+// DeleteAnnouncement takes live content down, so without publish permission only drafts and rejected items go
+func (s *announcementService) DeleteAnnouncement(ctx context.Context, input AnnouncementDetailInput, canPublish bool) error {
+	data, err := s.repository.DetailAnnouncement(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+	if !canPublish && !editableWithoutPublish(data) {
+		return errNeedsPublish
+	}
+
+	err = s.repository.DeleteAnnouncement(ctx, input.ID)
 	if err != nil {
 		return err
 	}
+	if IsPublic(data) {
+		s.emitter.Emit(ctx, webhook.EventAnnouncementDeleted, webhook.DeletedData{ID: input.ID})
+	}
 	return nil
 }
 
-func (s *announcementService) UpdateAnnouncement(input AnnouncementDetailInput, updateData AnnouncementUpdateInput, updatePath string, s3Client s3.Client) (model.Announcement, error) {
-	data, err := s.repository.DetailAnnouncement(input.ID)
+func (s *announcementService) UpdateAnnouncement(ctx context.Context, input AnnouncementDetailInput, updateData AnnouncementUpdateInput, banner BannerInput) (model.Announcement, error) {
+	data, err := s.repository.DetailAnnouncement(ctx, input.ID)
 	if err != nil {
-		return data, nil
+		return data, err
 	}
-	if updatePath != "" {
-		data.Images = updatePath
+	if !updateData.CanPublish {
+		if !editableWithoutPublish(data) {
+			return data, errNeedsPublish
+		}
+		if updateData.Status != "" && updateData.Status != StatusDraft {
+			return data, errNeedsPublish
+		}
+	}
+
+	wasPublic := IsPublic(data)
+
+	// announcements created before revisions existed get their current state as the first revision
+	revisions, errRevisions := s.repository.GetRevisions(ctx, data.ID)
+	if errRevisions != nil {
+		return data, errRevisions
+	}
+	if len(revisions) == 0 {
+		errBaseline := s.saveRevision(ctx, data, data.UserID)
+		if errBaseline != nil {
+			return data, errBaseline
+		}
+	}
+
+	if banner.Large != "" {
+		data.Images = banner.Large
+		data.ImageMedium = banner.Medium
+		data.ImageThumbnail = banner.Thumbnail
 	}
 
 	if updateData.Title != "" {
 		data.Title = updateData.Title
-
-		convertTitleToLowerCase := strings.ToLower(updateData.Title)
-		sliceTitle := strings.Split(convertTitleToLowerCase, " ")
-		slug := strings.Join(sliceTitle, "-")
-		data.Slug = slug
 	}
 
 	if updateData.Description != "" {
 		data.Description = updateData.Description
 	}
 
-	update, errUpdate := s.repository.Update(data, s3Client)
+	if updateData.Status != "" || updateData.PublishAt != "" || updateData.ExpireAt != "" {
+		// a new expire_at brings an expired announcement back, which is publishing it again
+		if updateData.CanPublish && updateData.Status == "" && updateData.ExpireAt != "" && data.Status == StatusExpired {
+			data.Status = StatusPublished
+		}
+		errStatus := applyStatus(&data, updateData.Status, updateData.PublishAt, updateData.ExpireAt)
+		if errStatus != nil {
+			return data, errStatus
+		}
+	}
+
+	var update model.Announcement
+	save := func() error {
+		var errSave error
+		update, errSave = s.repository.Update(ctx, data)
+		return errSave
+	}
+	var errUpdate error
+	if updateData.Title != "" {
+		errUpdate = s.slugService.SaveWithSlug(ctx, slug.TableAnnouncements, updateData.Title, data.ID, func(announcementSlug string) error {
+			data.Slug = announcementSlug
+			return save()
+		})
+	} else {
+		errUpdate = save()
+	}
+	if errUpdate != nil {
+		return update, errUpdate
+	}
+
+	errRevision := s.saveRevision(ctx, update, updateData.UserID)
+	if errRevision != nil {
+		return update, errRevision
+	}
+	s.announceChange(ctx, wasPublic, update)
+
+	return update, nil
+}
+
+func (s *announcementService) GetRevisions(ctx context.Context, input AnnouncementDetailInput) ([]model.AnnouncementRevision, error) {
+	revisions, err := s.repository.GetRevisions(ctx, input.ID)
+	if err != nil {
+		return revisions, err
+	}
+	return revisions, nil
+}
+
+func (s *announcementService) DiffRevisions(ctx context.Context, input AnnouncementDetailInput, diffInput AnnouncementRevisionDiffInput) ([]RevisionFieldDiff, error) {
+	from, err := s.repository.GetRevision(ctx, input.ID, diffInput.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.repository.GetRevision(ctx, input.ID, diffInput.To)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []RevisionFieldDiff{
+		{Field: "title", From: from.Title, To: to.Title},
+		{Field: "description", From: from.Description, To: to.Description},
+		{Field: "banner", From: from.Images, To: to.Images},
+		{Field: "slug", From: from.Slug, To: to.Slug},
+	}
+
+	diff := []RevisionFieldDiff{}
+	for _, field := range fields {
+		if field.From != field.To {
+			diff = append(diff, field)
+		}
+	}
+	return diff, nil
+}
+
+// RollbackRevision copies an old revision back onto the announcement and records it as a new revision
+func (s *announcementService) RollbackRevision(ctx context.Context, input AnnouncementRevisionInput, userID uint, canPublish bool) (model.Announcement, error) {
+	revision, err := s.repository.GetRevision(ctx, input.ID, input.Revision)
+	if err != nil {
+		return model.Announcement{}, err
+	}
+
+	data, err := s.repository.DetailAnnouncement(ctx, input.ID)
+	if err != nil {
+		return data, err
+	}
+	if !canPublish && !editableWithoutPublish(data) {
+		return data, errNeedsPublish
+	}
+	wasPublic := IsPublic(data)
+
+	data.Title = revision.Title
+	data.Description = revision.Description
+	data.Images = revision.Images
+	data.ImageMedium = revision.ImageMedium
+	data.ImageThumbnail = revision.ImageThumbnail
+
+	var update model.Announcement
+	errUpdate := s.slugService.SaveWithSlug(ctx, slug.TableAnnouncements, revision.Title, data.ID, func(announcementSlug string) error {
+		data.Slug = announcementSlug
+		var errSave error
+		update, errSave = s.repository.Update(ctx, data)
+		return errSave
+	})
 	if errUpdate != nil {
 		return update, errUpdate
 	}
 
+	errRevision := s.saveRevision(ctx, update, userID)
+	if errRevision != nil {
+		return update, errRevision
+	}
+	s.announceChange(ctx, wasPublic, update)
+
 	return update, nil
 }
+
+// saveRevision records the revision and tells the media library which banners are in use,
+// both the announcement and the revision keep their banner out of the garbage collector
+func (s *announcementService) saveRevision(ctx context.Context, announcement model.Announcement, userID uint) error {
+	revision, err := s.repository.SaveRevision(ctx, announcement, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.mediaService.SyncReferences(ctx, media.EntityAnnouncement, announcement.ID, media.FieldBanner, announcement.Images, announcement.ImageMedium, announcement.ImageThumbnail)
+	if err != nil {
+		return err
+	}
+	return s.mediaService.SyncReferences(ctx, media.EntityAnnouncementRevision, revision.ID, media.FieldBanner, revision.Images, revision.ImageMedium, revision.ImageThumbnail)
+}
+
+// CountScheduled is the number of announcements waiting for their publish time
+func (s *announcementService) CountScheduled(ctx context.Context) (int64, error) {
+	return s.repository.CountScheduled(ctx)
+}
+
+// RefreshStatuses publishes scheduled announcements whose time has come and expires the ones past expire_at
+func (s *announcementService) RefreshStatuses(ctx context.Context) (int, int, error) {
+	now := time.Now()
+
+	published, err := s.repository.PublishDue(ctx, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, item := range published {
+		s.announceChange(ctx, false, item)
+	}
+
+	expired, err := s.repository.ExpireDue(ctx, now)
+	if err != nil {
+		return len(published), expired, err
+	}
+
+	return len(published), expired, nil
+}
+
+func (s *announcementService) SubmitForReview(ctx context.Context, tx *gorm.DB, ID uint, submitter review.Submitter) (review.ReviewContent, error) {
+	data, err := s.repository.DetailForUpdate(ctx, tx, ID)
+	if err != nil {
+		return review.ReviewContent{}, err
+	}
+	if !submitter.CanPublish && data.UserID != submitter.UserID {
+		return review.ReviewContent{}, apperr.Forbidden("only the author or a publisher can submit this announcement")
+	}
+	if data.Status != StatusDraft && data.Status != StatusRejected {
+		return review.ReviewContent{}, apperr.Conflict("announcement_not_submittable", "only draft or rejected announcement can be submitted")
+	}
+
+	data.Status = StatusInReview
+	errUpdate := s.repository.UpdateStatus(ctx, tx, data)
+	if errUpdate != nil {
+		return review.ReviewContent{}, errUpdate
+	}
+
+	return review.ReviewContent{Title: data.Title, AuthorID: data.UserID}, nil
+}
+
+// ApproveReview publishes the announcement, or schedules it when publish_at is still ahead
+func (s *announcementService) ApproveReview(ctx context.Context, tx *gorm.DB, ID uint) error {
+	data, err := s.repository.DetailForUpdate(ctx, tx, ID)
+	if err != nil {
+		return err
+	}
+	if data.Status != StatusInReview {
+		return errNotInReview
+	}
+
+	errStatus := applyStatus(&data, StatusPublished, "", "")
+	if errStatus != nil {
+		return errStatus
+	}
+
+	return s.repository.UpdateStatus(ctx, tx, data)
+}
+
+func (s *announcementService) RejectReview(ctx context.Context, tx *gorm.DB, ID uint) error {
+	data, err := s.repository.DetailForUpdate(ctx, tx, ID)
+	if err != nil {
+		return err
+	}
+	if data.Status != StatusInReview {
+		return errNotInReview
+	}
+
+	data.Status = StatusRejected
+	return s.repository.UpdateStatus(ctx, tx, data)
+}
+
+// ReviewDecided tells subscribers about an approved announcement once the decision is committed
+func (s *announcementService) ReviewDecided(ctx context.Context, ID uint, status string) {
+	if status != review.StatusApproved {
+		return
+	}
+	data, err := s.repository.DetailAnnouncement(ctx, ID)
+	if err != nil {
+		log.Printf("announcement %d approved but not loaded for notifications: %v", ID, err)
+		return
+	}
+	s.announceChange(ctx, false, data)
+}
+
+// announceChange tells webhook subscribers and push devices only about what visitors can see. Going public
+// is announcement.created, whether by creating, approving, a scheduled publish_at or an edit, changes to
+// public content are announcement.updated, drafts and content waiting for review never leave the api
+func (s *announcementService) announceChange(ctx context.Context, wasPublic bool, announcement model.Announcement) {
+	if !wasPublic && IsPublic(announcement) {
+		s.emitter.Emit(ctx, webhook.EventAnnouncementCreated, AnnouncementListFormat(announcement))
+		s.publishNotifier.AnnouncementPublished(ctx, announcement)
+		return
+	}
+	if wasPublic {
+		s.emitter.Emit(ctx, webhook.EventAnnouncementUpdated, AnnouncementListFormat(announcement))
+	}
+}
+
+// editableWithoutPublish is true for announcements that are not live and not waiting for review
+func editableWithoutPublish(announcement model.Announcement) bool {
+	return announcement.Status == StatusDraft || announcement.Status == StatusRejected
+}
+
+// IsPublic tells whether an announcement may be shown to visitors who are not logged in
+func IsPublic(announcement model.Announcement) bool {
+	now := time.Now()
+	if announcement.Status != StatusPublished {
+		return false
+	}
+	if announcement.PublishAt != nil && announcement.PublishAt.After(now) {
+		return false
+	}
+	if announcement.ExpireAt != nil && !announcement.ExpireAt.After(now) {
+		return false
+	}
+	return true
+}
+
+func applyStatus(announcement *model.Announcement, status string, publishAt string, expireAt string) error {
+	if publishAt != "" {
+		parsed, err := time.Parse(time.RFC3339, publishAt)
+		if err != nil {
+			return apperr.Validation("invalid_publish_at", "publish_at must be an RFC 3339 time")
+		}
+		announcement.PublishAt = &parsed
+	}
+
+	if expireAt != "" {
+		parsed, err := time.Parse(time.RFC3339, expireAt)
+		if err != nil {
+			return apperr.Validation("invalid_expire_at", "expire_at must be an RFC 3339 time")
+		}
+		announcement.ExpireAt = &parsed
+	}
+
+	if status != "" {
+		announcement.Status = status
+	}
+	if announcement.Status == "" {
+		announcement.Status = StatusPublished
+	}
+
+	now := time.Now()
+	switch announcement.Status {
+	case StatusScheduled:
+		if announcement.PublishAt == nil {
+			return apperr.Validation("publish_at_required", "publish_at is required for scheduled announcement")
+		}
+		if !announcement.PublishAt.After(now) {
+			announcement.Status = StatusPublished
+		}
+	case StatusPublished:
+		if announcement.PublishAt == nil {
+			announcement.PublishAt = &now
+		} else if announcement.PublishAt.After(now) {
+			announcement.Status = StatusScheduled
+		}
+	}
+
+	if announcement.ExpireAt != nil && announcement.PublishAt != nil && !announcement.ExpireAt.After(*announcement.PublishAt) {
+		return apperr.Validation("expire_before_publish", "expire_at must be after publish_at")
+	}
+
+	if announcement.Status == StatusPublished && announcement.ExpireAt != nil && !announcement.ExpireAt.After(now) {
+		announcement.Status = StatusExpired
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package announcement
+
+import (
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/model"
+	"testing"
+	"time"
+)
+
+func TestApplyStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-48 * time.Hour).Format(time.RFC3339)
+	yesterday := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	tomorrow := now.Add(24 * time.Hour).Format(time.RFC3339)
+	nextWeek := now.Add(7 * 24 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		current       string
+		status        string
+		publishAt     string
+		expireAt      string
+		wantStatus    string
+		wantPublishAt bool
+		wantCode      string
+	}{
+		{name: "empty status publishes now", wantStatus: StatusPublished, wantPublishAt: true},
+		{name: "draft stays draft", status: StatusDraft, wantStatus: StatusDraft},
+		{name: "published in the future is scheduled", status: StatusPublished, publishAt: tomorrow, wantStatus: StatusScheduled, wantPublishAt: true},
+		{name: "scheduled in the past is published", status: StatusScheduled, publishAt: yesterday, wantStatus: StatusPublished, wantPublishAt: true},
+		{name: "scheduled in the future", status: StatusScheduled, publishAt: tomorrow, expireAt: nextWeek, wantStatus: StatusScheduled, wantPublishAt: true},
+		{name: "scheduled needs publish_at", status: StatusScheduled, wantCode: "publish_at_required"},
+		{name: "already expired", status: StatusPublished, publishAt: past, expireAt: yesterday, wantStatus: StatusExpired, wantPublishAt: true},
+		{name: "expire before publish", publishAt: tomorrow, expireAt: yesterday, wantCode: "expire_before_publish"},
+		{name: "expire equal to publish", publishAt: tomorrow, expireAt: tomorrow, wantCode: "expire_before_publish"},
+		{name: "invalid publish_at", publishAt: "next friday", wantCode: "invalid_publish_at"},
+		{name: "invalid expire_at", expireAt: "2026-13-01", wantCode: "invalid_expire_at"},
+		{name: "current status kept when none is given", current: StatusDraft, wantStatus: StatusDraft},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			announcement := model.Announcement{Status: test.current}
+			err := applyStatus(&announcement, test.status, test.publishAt, test.expireAt)
+
+			if test.wantCode != "" {
+				typed, ok := err.(*apperr.Error)
+				if !ok || typed.Kind != apperr.KindValidation || typed.Code != test.wantCode {
+					t.Fatalf("got error %v, want validation %q", err, test.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if announcement.Status != test.wantStatus {
+				t.Errorf("got status %q, want %q", announcement.Status, test.wantStatus)
+			}
+			if (announcement.PublishAt != nil) != test.wantPublishAt {
+				t.Errorf("got publish_at %v", announcement.PublishAt)
+			}
+		})
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		status    string
+		publishAt *time.Time
+		expireAt  *time.Time
+		want      bool
+	}{
+		{"published", StatusPublished, &past, nil, true},
+		{"published without dates", StatusPublished, nil, nil, true},
+		{"published until later", StatusPublished, &past, &future, true},
+		{"publish time not reached", StatusPublished, &future, nil, false},
+		{"expire time passed", StatusPublished, nil, &past, false},
+		{"draft", StatusDraft, nil, nil, false},
+		{"in review", StatusInReview, &past, nil, false},
+		{"scheduled", StatusScheduled, &past, nil, false},
+		{"expired", StatusExpired, &past, nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			announcement := model.Announcement{Status: test.status, PublishAt: test.publishAt, ExpireAt: test.expireAt}
+			if got := IsPublic(announcement); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package apperr
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// Kind decides the HTTP status of an error, Code tells clients which error it is exactly
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindUnavailable  Kind = "unavailable"
+	KindInternal     Kind = "internal"
+)
+
+// generic codes, domain packages add their own next to the errors they return
+const (
+	CodeInvalidInput = "invalid_input"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeInternal     = "internal_error"
+)
+
+var statuses = map[Kind]int{
+	KindValidation:   http.StatusUnprocessableEntity,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindUnavailable:  http.StatusServiceUnavailable,
+	KindInternal:     http.StatusInternalServerError,
+}
+
+// Error is what services return for failures the client can act on, and what handlers
+// hand to the error middleware. Message is shown to the client, Err is only logged
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details []string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status is the HTTP status the middleware answers with
+func (e *Error) Status() int {
+	status, ok := statuses[e.Kind]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+func Validation(code string, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+func Unauthorized(code string, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Code: CodeForbidden, Message: message}
+}
+
+func NotFound(code string, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code string, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Input is a request that failed binding, the middleware lists every failed field in the client's language
+func Input(message string, err error) *Error {
+	return &Error{Kind: KindValidation, Code: CodeInvalidInput, Message: message, Err: err}
+}
+
+// Wrap puts the handler's message on top of a service error. Typed errors keep their kind and code
+// and move their own message to the details, missing rows become not found and anything else is
+// an internal error whose cause is logged but never sent
+func Wrap(err error, message string) *Error {
+	var typed *Error
+	if errors.As(err, &typed) {
+		details := typed.Details
+		if len(details) == 0 {
+			details = []string{typed.Message}
+		}
+		return &Error{Kind: typed.Kind, Code: typed.Code, Message: message, Details: details, Err: typed.Err}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &Error{Kind: KindNotFound, Code: CodeNotFound, Message: message, Err: err}
+	}
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: message, Err: err}
+}
+
+// Is tells whether err is an apperr of the given kind, for handlers that branch on it
+func Is(err error, kind Kind) bool {
+	var typed *Error
+	return errors.As(err, &typed) && typed.Kind == kind
+}
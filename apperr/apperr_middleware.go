@@ -0,0 +1,38 @@
+package apperr
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"nurul-iman-blok-m/helper"
+)
+
+// Middleware answers for the last error a handler recorded with c.Error, so handlers only decide
+// which error happened and return. Handlers that already wrote a response are left alone
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+		err, ok := last.Err.(*Error)
+		if !ok {
+			err = Wrap(last.Err, "Something went wrong")
+		}
+		if err.Kind == KindInternal {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+
+		var data interface{}
+		if err.Code == CodeInvalidInput && err.Err != nil {
+			locale := helper.ValidationLocale(c.GetHeader("Accept-Language"))
+			data = gin.H{"errors": helper.FormatValidationError(err.Err, locale)}
+		} else if len(err.Details) > 0 {
+			data = gin.H{"errors": err.Details}
+		}
+
+		status := err.Status()
+		c.AbortWithStatusJSON(status, helper.ApiErrorResponse(err.Message, status, err.Code, data))
+	}
+}
@@ -0,0 +1,175 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	tests := []struct {
+		name        string
+		err         error
+		wantKind    Kind
+		wantCode    string
+		wantDetails []string
+		wantCause   error
+	}{
+		{
+			name:        "typed error keeps kind and code",
+			err:         NotFound("announcement_not_found", "announcement not found"),
+			wantKind:    KindNotFound,
+			wantCode:    "announcement_not_found",
+			wantDetails: []string{"announcement not found"},
+		},
+		{
+			name:        "typed error keeps its details",
+			err:         &Error{Kind: KindConflict, Code: "slug_taken", Message: "conflict", Details: []string{"slug", "title"}},
+			wantKind:    KindConflict,
+			wantCode:    "slug_taken",
+			wantDetails: []string{"slug", "title"},
+		},
+		{
+			name:        "wrapped typed error",
+			err:         fmt.Errorf("update: %w", Forbidden("not allowed")),
+			wantKind:    KindForbidden,
+			wantCode:    CodeForbidden,
+			wantDetails: []string{"not allowed"},
+		},
+		{
+			name:      "missing row",
+			err:       fmt.Errorf("find: %w", gorm.ErrRecordNotFound),
+			wantKind:  KindNotFound,
+			wantCode:  CodeNotFound,
+			wantCause: gorm.ErrRecordNotFound,
+		},
+		{
+			name:      "anything else is internal",
+			err:       cause,
+			wantKind:  KindInternal,
+			wantCode:  CodeInternal,
+			wantCause: cause,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Wrap(test.err, "Failed to load")
+			if got.Kind != test.wantKind || got.Code != test.wantCode {
+				t.Errorf("got %s/%s, want %s/%s", got.Kind, got.Code, test.wantKind, test.wantCode)
+			}
+			if got.Message != "Failed to load" {
+				t.Errorf("got message %q", got.Message)
+			}
+			if !reflect.DeepEqual(got.Details, test.wantDetails) {
+				t.Errorf("got details %v, want %v", got.Details, test.wantDetails)
+			}
+			if test.wantCause != nil && !errors.Is(got, test.wantCause) {
+				t.Errorf("cause %v lost", test.wantCause)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantCode   string
+		wantErrors interface{}
+	}{
+		{
+			name:       "validation",
+			handler:    func(c *gin.Context) { _ = c.Error(Validation("invalid_publish_at", "bad time")) },
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "invalid_publish_at",
+		},
+		{
+			name:       "unauthorized",
+			handler:    func(c *gin.Context) { _ = c.Error(Unauthorized("token_expired", "expired")) },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "token_expired",
+		},
+		{
+			name:       "wrapped service error lists the details",
+			handler:    func(c *gin.Context) { _ = c.Error(Wrap(Conflict("slug_taken", "slug taken"), "Failed to save")) },
+			wantStatus: http.StatusConflict,
+			wantCode:   "slug_taken",
+			wantErrors: []interface{}{"slug taken"},
+		},
+		{
+			name:       "plain error",
+			handler:    func(c *gin.Context) { _ = c.Error(errors.New("boom")) },
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+		},
+		{
+			name:       "missing row",
+			handler:    func(c *gin.Context) { _ = c.Error(gorm.ErrRecordNotFound) },
+			wantStatus: http.StatusNotFound,
+			wantCode:   CodeNotFound,
+		},
+		{
+			name:       "unknown kind",
+			handler:    func(c *gin.Context) { _ = c.Error(&Error{Kind: "teapot", Code: "teapot", Message: "teapot"}) },
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "teapot",
+		},
+		{
+			name: "written response is left alone",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusAccepted, gin.H{})
+				_ = c.Error(errors.New("logged only"))
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Middleware())
+			router.GET("/", test.handler)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d", recorder.Code, test.wantStatus)
+			}
+			if test.wantCode == "" {
+				return
+			}
+
+			var body struct {
+				Info struct {
+					Code  int    `json:"code"`
+					Error string `json:"error"`
+				} `json:"info"`
+				Data map[string]interface{} `json:"data"`
+			}
+			err := json.Unmarshal(recorder.Body.Bytes(), &body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body.Info.Code != test.wantStatus || body.Info.Error != test.wantCode {
+				t.Errorf("got info %+v", body.Info)
+			}
+			if !reflect.DeepEqual(body.Data["errors"], test.wantErrors) {
+				t.Errorf("got errors %v, want %v", body.Data["errors"], test.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package audit
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionSubmit  = "submit"
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+	ActionSend    = "send"
+
+	EntityAnnouncement = "announcement"
+	EntityRundown      = "rundown"
+	EntityArticle      = "article"
+	EntityRole         = "role"
+	EntityUser         = "user"
+	EntityRecording    = "recording"
+	EntityTemplate     = "message_template"
+)
+
+type AuditInput struct {
+	ActorID    uint
+	ActorName  string
+	IPAddress  string
+	Action     string
+	EntityType string
+	EntityID   uint
+	Before     interface{}
+	After      interface{}
+}
+
+type AuditFilterInput struct {
+	ActorID    uint   `form:"actor_id"`
+	Action     string `form:"action"`
+	EntityType string `form:"entity_type"`
+	EntityID   uint   `form:"entity_id"`
+	From       string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To         string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"encoding/json"
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type AuditFormatter struct {
+	ID         uint                   `json:"id"`
+	ActorID    uint                   `json:"actor_id"`
+	ActorName  string                 `json:"actor_name"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   uint                   `json:"entity_id"`
+	Changes    map[string]FieldChange `json:"changes"`
+	IPAddress  string                 `json:"ip_address"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+func AuditJsonFormatter(log model.AuditLog) AuditFormatter {
+	changes := map[string]FieldChange{}
+	_ = json.Unmarshal([]byte(log.Changes), &changes)
+
+	return AuditFormatter{
+		ID:         log.ID,
+		ActorID:    log.ActorID,
+		ActorName:  log.ActorName,
+		Action:     log.Action,
+		EntityType: log.EntityType,
+		EntityID:   log.EntityID,
+		Changes:    changes,
+		IPAddress:  log.IPAddress,
+		CreatedAt:  log.CreatedAt,
+	}
+}
+
+func ListAuditJsonFormatter(logs []model.AuditLog) []AuditFormatter {
+	formatter := []AuditFormatter{}
+
+	for _, log := range logs {
+		formatter = append(formatter, AuditJsonFormatter(log))
+	}
+
+	return formatter
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+)
+
+type AuditRepository interface {
+	SaveLog(ctx context.Context, log model.AuditLog) (model.AuditLog, error)
+	GetListLog(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.AuditLog, int, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *auditRepository {
+	return &auditRepository{db}
+}
+
+func (r *auditRepository) SaveLog(ctx context.Context, log model.AuditLog) (model.AuditLog, error) {
+	err := r.db.WithContext(ctx).Create(&log).Error
+	if err != nil {
+		return log, err
+	}
+	return log, nil
+}
+
+func (r *auditRepository) GetListLog(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.AuditLog, int, error) {
+	var logs []model.AuditLog
+	err := r.db.WithContext(ctx).Scopes(filter, list).Order("created_at desc").Find(&logs).Error
+	if err != nil {
+		return logs, 0, err
+	}
+
+	totalCount := int64(0)
+	r.db.WithContext(ctx).Model(&model.AuditLog{}).Scopes(filter).Count(&totalCount)
+	return logs, int(totalCount), nil
+}
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+	"reflect"
+	"time"
+)
+
+// fields left out of the diff, either noisy or secret
+var ignoredFields = map[string]bool{
+	"CreatedAt": true,
+	"UpdatedAt": true,
+	"Password":  true,
+}
+
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditService interface {
+	Record(ctx context.Context, input AuditInput) error
+	GetListLog(ctx context.Context, filter AuditFilterInput, list func(db *gorm.DB) *gorm.DB) ([]model.AuditLog, int, error)
+}
+
+type auditService struct {
+	repository AuditRepository
+}
+
+func NewService(repository AuditRepository) *auditService {
+	return &auditService{repository}
+}
+
+func (s *auditService) Record(ctx context.Context, input AuditInput) error {
+	changes, err := json.Marshal(Diff(input.Before, input.After))
+	if err != nil {
+		return err
+	}
+
+	auditLog := model.AuditLog{}
+	auditLog.ActorID = input.ActorID
+	auditLog.ActorName = input.ActorName
+	auditLog.Action = input.Action
+	auditLog.EntityType = input.EntityType
+	auditLog.EntityID = input.EntityID
+	auditLog.Changes = string(changes)
+	auditLog.IPAddress = input.IPAddress
+
+	_, errSave := s.repository.SaveLog(ctx, auditLog)
+	if errSave != nil {
+		return errSave
+	}
+	return nil
+}
+
+func (s *auditService) GetListLog(ctx context.Context, filter AuditFilterInput, list func(db *gorm.DB) *gorm.DB) ([]model.AuditLog, int, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		if filter.ActorID != 0 {
+			db = db.Where("actor_id = ?", filter.ActorID)
+		}
+		if filter.Action != "" {
+			db = db.Where("action = ?", filter.Action)
+		}
+		if filter.EntityType != "" {
+			db = db.Where("entity_type = ?", filter.EntityType)
+		}
+		if filter.EntityID != 0 {
+			db = db.Where("entity_id = ?", filter.EntityID)
+		}
+		if from, err := time.Parse("2006-01-02", filter.From); err == nil {
+			db = db.Where("created_at >= ?", from)
+		}
+		if to, err := time.Parse("2006-01-02", filter.To); err == nil {
+			db = db.Where("created_at < ?", to.AddDate(0, 0, 1))
+		}
+		return db
+	}
+
+	logs, count, err := s.repository.GetListLog(ctx, scope, list)
+	if err != nil {
+		return logs, 0, err
+	}
+	return logs, count, nil
+}
+
+// Diff compares the top level scalar fields of two snapshots, either side may be nil for create and delete
+func Diff(before interface{}, after interface{}) map[string]FieldChange {
+	beforeFields := snapshot(before)
+	afterFields := snapshot(after)
+	changes := map[string]FieldChange{}
+
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			changes[field] = FieldChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = FieldChange{Before: nil, After: value}
+		}
+	}
+
+	return changes
+}
+
+func snapshot(entity interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if entity == nil {
+		return fields
+	}
+
+	encoded, err := json.Marshal(entity)
+	if err != nil {
+		return fields
+	}
+	var decoded map[string]interface{}
+	if json.Unmarshal(encoded, &decoded) != nil {
+		return fields
+	}
+
+	for field, value := range decoded {
+		if ignoredFields[field] {
+			continue
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			// relations are audited on their own entity
+			continue
+		}
+		fields[field] = value
+	}
+	return fields
+}
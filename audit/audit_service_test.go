@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type auditedEntity struct {
+	ID        uint
+	Title     string
+	Status    string
+	Tags      []string
+	Author    *auditedAuthor
+	Password  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type auditedAuthor struct {
+	Name string
+}
+
+func TestDiff(t *testing.T) {
+	base := auditedEntity{ID: 1, Title: "Kajian", Status: "draft", Password: "old", CreatedAt: time.Now()}
+
+	changed := base
+	changed.Status = "published"
+	changed.Password = "new"
+	changed.UpdatedAt = time.Now().Add(time.Hour)
+	changed.Tags = []string{"fiqih"}
+	changed.Author = &auditedAuthor{Name: "Ustadz"}
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   map[string]FieldChange
+	}{
+		{
+			name:   "no change",
+			before: base,
+			after:  base,
+			want:   map[string]FieldChange{},
+		},
+		{
+			name:   "only scalar fields outside the ignore list",
+			before: base,
+			after:  changed,
+			want:   map[string]FieldChange{"Status": {Before: "draft", After: "published"}},
+		},
+		{
+			name:   "create",
+			before: nil,
+			after:  auditedAuthor{Name: "Ustadz"},
+			want:   map[string]FieldChange{"Name": {Before: nil, After: "Ustadz"}},
+		},
+		{
+			name:   "delete",
+			before: &auditedAuthor{Name: "Ustadz"},
+			after:  nil,
+			want:   map[string]FieldChange{"Name": {Before: "Ustadz", After: nil}},
+		},
+		{
+			name:   "numbers compare after encoding",
+			before: map[string]interface{}{"ID": 1, "Count": 2},
+			after:  map[string]interface{}{"ID": uint(1), "Count": 3},
+			want:   map[string]FieldChange{"Count": {Before: float64(2), After: float64(3)}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Diff(test.before, test.after)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package auth
 import (
 	"errors"
 	"github.com/golang-jwt/jwt/v4"
-	"os"
 )
 
 type Service interface {
@@ -12,10 +11,17 @@ type Service interface {
 }
 
 type jwtService struct {
+	secret   []byte
+	previous []byte
 }
 
-func NewService() *jwtService {
-	return &jwtService{}
+// NewService signs with secret, tokens signed with the previous secret stay valid until it is removed
+func NewService(secret string, previous string) *jwtService {
+	service := &jwtService{secret: []byte(secret)}
+	if previous != "" {
+		service.previous = []byte(previous)
+	}
+	return service
 }
 
 func (s *jwtService) GenerateToken(userId uint) (string, error) {
@@ -23,7 +29,7 @@ func (s *jwtService) GenerateToken(userId uint) (string, error) {
 	claim["user_id"] = userId
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
-	signedToken, err := token.SignedString([]byte(os.Getenv("API_SECRET")))
+	signedToken, err := token.SignedString(s.secret)
 	if err != nil {
 		return signedToken, err
 	}
@@ -32,14 +38,10 @@ func (s *jwtService) GenerateToken(userId uint) (string, error) {
 }
 
 func (s *jwtService) ValidateToken(encodedToken string) (*jwt.Token, error) {
-	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok {
-			return nil, errors.New("invalid Token")
-		}
-
-		return []byte(os.Getenv("API_SECRET")), nil
-	})
+	token, err := parseToken(encodedToken, s.secret)
+	if err != nil && s.previous != nil && errors.Is(err, jwt.ErrSignatureInvalid) {
+		token, err = parseToken(encodedToken, s.previous)
+	}
 
 	if err != nil {
 		return token, err
@@ -47,3 +49,14 @@ func (s *jwtService) ValidateToken(encodedToken string) (*jwt.Token, error) {
 
 	return token, nil
 }
+
+func parseToken(encodedToken string, secret []byte) (*jwt.Token, error) {
+	return jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodHMAC)
+		if !ok {
+			return nil, errors.New("invalid Token")
+		}
+
+		return secret, nil
+	})
+}
@@ -0,0 +1,21 @@
+package broadcast
+
+type TemplateNameInput struct {
+	Name string `uri:"name" binding:"required,oneof=announcement weekly_rundown"`
+}
+
+type TemplateInput struct {
+	Body string `json:"body" binding:"required,max=4000"`
+}
+
+type WeeklyShareInput struct {
+	Week string `form:"week" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type SendInput struct {
+	Target string `json:"target" binding:"max=100"`
+}
+
+type ShortLinkInput struct {
+	Code string `uri:"code" binding:"required,alphanum,max=20"`
+}
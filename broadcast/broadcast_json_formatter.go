@@ -0,0 +1,46 @@
+package broadcast
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+type ShareFormatter struct {
+	Text        string `json:"text"`
+	WhatsAppURL string `json:"whatsapp_url"`
+}
+
+type TemplateFormatter struct {
+	Name      string     `json:"name"`
+	Body      string     `json:"body"`
+	Custom    bool       `json:"custom"`
+	UpdatedBy string     `json:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// ShareJsonFormatter also returns a wa.me link that opens WhatsApp with the text filled in
+func ShareJsonFormatter(text string) ShareFormatter {
+	return ShareFormatter{
+		Text:        text,
+		WhatsAppURL: "https://wa.me/?text=" + strings.ReplaceAll(url.QueryEscape(text), "+", "%20"),
+	}
+}
+
+func TemplateJsonFormatter(template Template) TemplateFormatter {
+	return TemplateFormatter{
+		Name:      template.Name,
+		Body:      template.Body,
+		Custom:    template.Custom,
+		UpdatedBy: template.UpdatedBy,
+		UpdatedAt: template.UpdatedAt,
+	}
+}
+
+func ListTemplateJsonFormatter(templates []Template) []TemplateFormatter {
+	var formatter []TemplateFormatter
+	for _, template := range templates {
+		formatter = append(formatter, TemplateJsonFormatter(template))
+	}
+	return formatter
+}
@@ -0,0 +1,67 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+)
+
+type BroadcastRepository interface {
+	FindTemplate(ctx context.Context, name string) (model.MessageTemplate, bool, error)
+	GetTemplates(ctx context.Context) ([]model.MessageTemplate, error)
+	SaveTemplate(ctx context.Context, template model.MessageTemplate) (model.MessageTemplate, error)
+	DeleteTemplate(ctx context.Context, name string) error
+	GetRundownsOn(ctx context.Context, dates []string) ([]model.StudyRundown, error)
+}
+
+type broadcastRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *broadcastRepository {
+	return &broadcastRepository{db}
+}
+
+// FindTemplate reports false when the template was never edited and the built-in one applies
+func (r *broadcastRepository) FindTemplate(ctx context.Context, name string) (model.MessageTemplate, bool, error) {
+	var template model.MessageTemplate
+	err := r.db.WithContext(ctx).Preload("User").Where("name = ?", name).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return template, false, nil
+	}
+	if err != nil {
+		return template, false, err
+	}
+	return template, true, nil
+}
+
+func (r *broadcastRepository) GetTemplates(ctx context.Context) ([]model.MessageTemplate, error) {
+	var templates []model.MessageTemplate
+	err := r.db.WithContext(ctx).Preload("User").Find(&templates).Error
+	if err != nil {
+		return templates, err
+	}
+	return templates, nil
+}
+
+func (r *broadcastRepository) SaveTemplate(ctx context.Context, template model.MessageTemplate) (model.MessageTemplate, error) {
+	err := r.db.WithContext(ctx).Omit("User").Save(&template).Error
+	if err != nil {
+		return template, err
+	}
+	return template, nil
+}
+
+func (r *broadcastRepository) DeleteTemplate(ctx context.Context, name string) error {
+	return r.db.WithContext(ctx).Where("name = ?", name).Delete(&model.MessageTemplate{}).Error
+}
+
+func (r *broadcastRepository) GetRundownsOn(ctx context.Context, dates []string) ([]model.StudyRundown, error) {
+	var rundowns []model.StudyRundown
+	err := r.db.WithContext(ctx).Preload("User").Where("schedule_date IN ?", dates).Find(&rundowns).Error
+	if err != nil {
+		return rundowns, err
+	}
+	return rundowns, nil
+}
@@ -0,0 +1,257 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/study_rundown"
+	"sort"
+	"strings"
+	"time"
+)
+
+const sendTimeout = 30 * time.Second
+
+// Template is a message template as the editor sees it, Custom is false for the built-in text
+type Template struct {
+	Name      string
+	Body      string
+	Custom    bool
+	UpdatedBy string
+	UpdatedAt *time.Time
+}
+
+type BroadcastService interface {
+	ShareAnnouncement(ctx context.Context, announcement model.Announcement) (string, error)
+	ShareWeeklyRundown(ctx context.Context, input WeeklyShareInput) (string, error)
+	Send(ctx context.Context, input SendInput, text string) error
+	GetTemplates(ctx context.Context) ([]Template, error)
+	UpdateTemplate(ctx context.Context, input TemplateNameInput, body TemplateInput, userID uint) (Template, error)
+	ResetTemplate(ctx context.Context, input TemplateNameInput) (Template, error)
+	Preview(ctx context.Context, input TemplateNameInput, body TemplateInput) (string, error)
+}
+
+type broadcastService struct {
+	repository  BroadcastRepository
+	broadcaster Broadcaster
+	shortURL    string
+	siteURL     string
+	location    *time.Location
+}
+
+// NewService takes the base of short links, e.g. https://api.example.org/s, and the public site for their targets
+func NewService(repository BroadcastRepository, broadcaster Broadcaster, shortURL string, siteURL string, location *time.Location) *broadcastService {
+	return &broadcastService{repository, broadcaster, strings.TrimRight(shortURL, "/"), strings.TrimRight(siteURL, "/"), location}
+}
+
+func (s *broadcastService) ShareAnnouncement(ctx context.Context, announcement model.Announcement) (string, error) {
+	publishedAt := announcement.CreatedAt
+	if announcement.PublishAt != nil {
+		publishedAt = *announcement.PublishAt
+	}
+
+	return s.render(ctx, TemplateAnnouncement, "", AnnouncementData{
+		Title:       announcement.Title,
+		Summary:     plainSummary(announcement.Description),
+		Link:        s.ShortLink(LinkAnnouncement, announcement.ID),
+		PublishedAt: publishedAt.In(s.location),
+	})
+}
+
+func (s *broadcastService) ShareWeeklyRundown(ctx context.Context, input WeeklyShareInput) (string, error) {
+	day := time.Now().In(s.location)
+	if input.Week != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", input.Week, s.location)
+		if err != nil {
+			return "", err
+		}
+		day = parsed
+	}
+
+	data, err := s.weeklyRundown(ctx, helper.StartOfWeek(day))
+	if err != nil {
+		return "", err
+	}
+	return s.render(ctx, TemplateWeeklyRundown, "", data)
+}
+
+func (s *broadcastService) weeklyRundown(ctx context.Context, weekStart time.Time) (WeeklyRundownData, error) {
+	data := WeeklyRundownData{
+		WeekStart: weekStart,
+		WeekEnd:   weekStart.AddDate(0, 0, 6),
+		Link:      s.ShortLink(LinkWeeklyRundown, 0),
+	}
+
+	var dates []string
+	for i := 0; i < 7; i++ {
+		dates = append(dates, study_rundown.ScheduleDates(weekStart.AddDate(0, 0, i))...)
+	}
+	rundowns, err := s.repository.GetRundownsOn(ctx, dates)
+	if err != nil {
+		return data, err
+	}
+
+	for i := 0; i < 7; i++ {
+		date := weekStart.AddDate(0, 0, i)
+		sameDay := map[string]bool{}
+		for _, value := range study_rundown.ScheduleDates(date) {
+			sameDay[value] = true
+		}
+
+		day := RundownDay{Date: date}
+		for _, rundown := range rundowns {
+			if !sameDay[strings.TrimSpace(rundown.ScheduleDate)] {
+				continue
+			}
+			startAt := strings.TrimSpace(rundown.Time)
+			if start, ok := study_rundown.StartTime(rundown, s.location); ok {
+				startAt = start.Format("15.04 MST")
+			}
+			day.Rundowns = append(day.Rundowns, RundownItem{Title: rundown.Title, Speaker: rundown.User.Name, Time: startAt})
+		}
+		if len(day.Rundowns) == 0 {
+			continue
+		}
+		sort.Slice(day.Rundowns, func(a, b int) bool {
+			return day.Rundowns[a].Time < day.Rundowns[b].Time
+		})
+		data.Days = append(data.Days, day)
+	}
+	return data, nil
+}
+
+func (s *broadcastService) Send(ctx context.Context, input SendInput, text string) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return s.broadcaster.Broadcast(ctx, input.Target, text)
+}
+
+func (s *broadcastService) GetTemplates(ctx context.Context) ([]Template, error) {
+	saved, err := s.repository.GetTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	custom := map[string]model.MessageTemplate{}
+	for _, item := range saved {
+		custom[item.Name] = item
+	}
+
+	var templates []Template
+	for _, name := range Templates {
+		if item, ok := custom[name]; ok {
+			templates = append(templates, customTemplate(item))
+			continue
+		}
+		templates = append(templates, Template{Name: name, Body: defaultTemplates[name]})
+	}
+	return templates, nil
+}
+
+// UpdateTemplate only stores a body that renders with sample data, a broken template would break sharing
+func (s *broadcastService) UpdateTemplate(ctx context.Context, input TemplateNameInput, body TemplateInput, userID uint) (Template, error) {
+	_, err := s.Preview(ctx, input, body)
+	if err != nil {
+		return Template{}, err
+	}
+
+	template, _, err := s.repository.FindTemplate(ctx, input.Name)
+	if err != nil {
+		return Template{}, err
+	}
+	template.Name = input.Name
+	template.Body = body.Body
+	template.UserID = userID
+
+	_, err = s.repository.SaveTemplate(ctx, template)
+	if err != nil {
+		return Template{}, err
+	}
+
+	saved, _, err := s.repository.FindTemplate(ctx, input.Name)
+	if err != nil {
+		return Template{}, err
+	}
+	return customTemplate(saved), nil
+}
+
+func (s *broadcastService) ResetTemplate(ctx context.Context, input TemplateNameInput) (Template, error) {
+	err := s.repository.DeleteTemplate(ctx, input.Name)
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{Name: input.Name, Body: defaultTemplates[input.Name]}, nil
+}
+
+// Preview renders a template body against sample data so the editor can show the result before saving
+func (s *broadcastService) Preview(ctx context.Context, input TemplateNameInput, body TemplateInput) (string, error) {
+	var sample interface{}
+	now := time.Now().In(s.location)
+	switch input.Name {
+	case TemplateAnnouncement:
+		sample = AnnouncementData{
+			Title:       "Kerja Bakti Bersih Masjid",
+			Summary:     "Mari bersama membersihkan masjid menjelang Ramadhan. Peralatan kebersihan disediakan panitia.",
+			Link:        s.ShortLink(LinkAnnouncement, 1),
+			PublishedAt: now,
+		}
+	case TemplateWeeklyRundown:
+		weekStart := helper.StartOfWeek(now)
+		sample = WeeklyRundownData{
+			WeekStart: weekStart,
+			WeekEnd:   weekStart.AddDate(0, 0, 6),
+			Link:      s.ShortLink(LinkWeeklyRundown, 0),
+			Days: []RundownDay{
+				{Date: weekStart, Rundowns: []RundownItem{{Title: "Tafsir Al-Qur'an", Speaker: "Ustadz Ahmad", Time: "18.30 WIB"}}},
+				{Date: weekStart.AddDate(0, 0, 3), Rundowns: []RundownItem{{Title: "Fiqih Muamalah", Speaker: "Ustadz Yusuf", Time: "05.15 WIB"}}},
+			},
+		}
+	default:
+		return "", apperr.NotFound("template_not_found", "unknown message template")
+	}
+
+	return s.render(ctx, input.Name, body.Body, sample)
+}
+
+// render uses body when given, otherwise the edited template or the built-in one
+func (s *broadcastService) render(ctx context.Context, name string, body string, data interface{}) (string, error) {
+	if body == "" {
+		saved, found, err := s.repository.FindTemplate(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		body = defaultTemplates[name]
+		if found {
+			body = saved.Body
+		}
+	}
+
+	parsed, err := parseTemplate(name, body)
+	if err != nil {
+		return "", apperr.Validation("invalid_template", "invalid template: "+err.Error())
+	}
+
+	var text bytes.Buffer
+	err = parsed.Execute(&text, data)
+	if err != nil {
+		return "", apperr.Validation("invalid_template", "invalid template: "+err.Error())
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(text.String(), "\n\n")), nil
+}
+
+func (s *broadcastService) ShortLink(kind string, ID uint) string {
+	return s.shortURL + "/" + ShortCode(kind, ID)
+}
+
+func customTemplate(template model.MessageTemplate) Template {
+	updatedAt := template.UpdatedAt
+	return Template{
+		Name:      template.Name,
+		Body:      template.Body,
+		Custom:    true,
+		UpdatedBy: template.User.Name,
+		UpdatedAt: &updatedAt,
+	}
+}
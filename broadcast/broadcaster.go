@@ -0,0 +1,28 @@
+package broadcast
+
+import (
+	"context"
+	"log"
+)
+
+// Broadcaster delivers a composed message to a group of jamaah, e.g. a WhatsApp group or channel
+type Broadcaster interface {
+	Broadcast(ctx context.Context, target string, text string) error
+}
+
+type logBroadcaster struct {
+	defaultTarget string
+}
+
+// NewLogBroadcaster only logs the message, until a WhatsApp gateway is connected admins copy the text by hand
+func NewLogBroadcaster(defaultTarget string) *logBroadcaster {
+	return &logBroadcaster{defaultTarget}
+}
+
+func (b *logBroadcaster) Broadcast(ctx context.Context, target string, text string) error {
+	if target == "" {
+		target = b.defaultTarget
+	}
+	log.Printf("broadcast to %q:\n%s", target, text)
+	return nil
+}
@@ -0,0 +1,142 @@
+package broadcast
+
+import (
+	"html"
+	"nurul-iman-blok-m/helper"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	TemplateAnnouncement  = "announcement"
+	TemplateWeeklyRundown = "weekly_rundown"
+
+	// kinds of short links, see ShortCode
+	LinkAnnouncement  = "a"
+	LinkWeeklyRundown = "w"
+
+	summaryLength = 280
+)
+
+var Templates = []string{TemplateAnnouncement, TemplateWeeklyRundown}
+
+// defaultTemplates are used until an admin edits them, the text follows WhatsApp markup:
+// *bold*, _italic_, ~strike~ and ```monospace```
+var defaultTemplates = map[string]string{
+	TemplateAnnouncement: `📢 {{bold .Title}}
+
+{{.Summary}}
+
+🔗 {{.Link}}
+
+{{italic "Masjid Nurul Iman Blok M"}}`,
+
+	TemplateWeeklyRundown: `🕌 {{bold "Jadwal Kajian Pekan Ini"}}
+{{italic (printf "%s - %s" (date .WeekStart) (date .WeekEnd))}}
+{{range .Days}}
+🗓️ {{bold (date .Date)}}
+{{range .Rundowns}}▪️ {{.Time}} {{.Title}}
+   👤 {{italic .Speaker}}
+{{end}}{{else}}
+Belum ada kajian terjadwal pekan ini.
+{{end}}
+🔗 {{.Link}}
+
+{{italic "Masjid Nurul Iman Blok M"}}`,
+}
+
+type AnnouncementData struct {
+	Title       string
+	Summary     string
+	Link        string
+	PublishedAt time.Time
+}
+
+type WeeklyRundownData struct {
+	WeekStart time.Time
+	WeekEnd   time.Time
+	Days      []RundownDay
+	Link      string
+}
+
+type RundownDay struct {
+	Date     time.Time
+	Rundowns []RundownItem
+}
+
+type RundownItem struct {
+	Title   string
+	Speaker string
+	Time    string
+}
+
+var (
+	htmlTags   = regexp.MustCompile(`<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	shortCode  = regexp.MustCompile(`^([a-z])([0-9a-z]*)$`)
+)
+
+var templateFuncs = template.FuncMap{
+	"bold":   func(text string) string { return wrap("*", text) },
+	"italic": func(text string) string { return wrap("_", text) },
+	"strike": func(text string) string { return wrap("~", text) },
+	"mono":   func(text string) string { return wrap("```", text) },
+	"date":   func(value time.Time) string { return helper.FormatDate("id", value) },
+	"upper":  strings.ToUpper,
+}
+
+// wrap applies a WhatsApp marker, which only works on one line without spaces right inside the marker
+func wrap(marker string, text string) string {
+	text = strings.TrimSpace(strings.Join(strings.Fields(text), " "))
+	if text == "" {
+		return ""
+	}
+	return marker + text + marker
+}
+
+func parseTemplate(name string, body string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(body)
+}
+
+// plainSummary turns the announcement description into chat text, cut on a word boundary
+func plainSummary(description string) string {
+	text := html.UnescapeString(htmlTags.ReplaceAllString(description, ""))
+	text = strings.TrimSpace(blankLines.ReplaceAllString(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n"))
+
+	runes := []rune(text)
+	if len(runes) <= summaryLength {
+		return text
+	}
+	cut := string(runes[:summaryLength])
+	if space := strings.LastIndexAny(cut, " \n"); space > summaryLength/2 {
+		cut = cut[:space]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
+
+// ShortCode is the path of a short link: a kind letter followed by the id in base 36, "a1z" or "w"
+func ShortCode(kind string, ID uint) string {
+	if ID == 0 {
+		return kind
+	}
+	return kind + strconv.FormatUint(uint64(ID), 36)
+}
+
+// ParseShortCode reverses ShortCode, ID is 0 for links without one
+func ParseShortCode(code string) (string, uint, bool) {
+	match := shortCode.FindStringSubmatch(strings.ToLower(code))
+	if match == nil {
+		return "", 0, false
+	}
+	if match[2] == "" {
+		return match[1], 0, true
+	}
+	ID, err := strconv.ParseUint(match[2], 36, 32)
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], uint(ID), true
+}
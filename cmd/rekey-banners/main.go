@@ -0,0 +1,169 @@
+// rekey-banners moves banners uploaded with the old date based keys to content addressed keys
+// and rewrites every announcement and revision row that points at them.
+//
+//	go run ./cmd/rekey-banners -dry-run
+//	go run ./cmd/rekey-banners
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/url"
+	"nurul-iman-blok-m/config"
+	"nurul-iman-blok-m/database"
+	"nurul-iman-blok-m/imaging"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/storage"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+)
+
+var imageColumns = []string{"images", "image_medium", "image_thumbnail"}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only print the planned renames")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Storage.Driver != "s3" {
+		log.Fatal("rekey-banners only works with STORAGE_DRIVER=s3")
+	}
+
+	// ctrl+c stops after the current object, rows are only rewritten once its copy exists
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	db := database.Db(cfg.Database)
+	client, err := storage.NewS3Client(ctx, cfg.AWS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	legacyURLs, err := collectLegacyURLs(ctx, db, cfg.AWS.PublicURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%d banner objects to rekey", len(legacyURLs))
+
+	failed := 0
+	for _, oldURL := range legacyURLs {
+		if ctx.Err() != nil {
+			log.Fatal("interrupted, run again to rekey the rest")
+		}
+		errRekey := rekey(ctx, db, client, cfg.AWS, oldURL, *dryRun)
+		if errRekey != nil {
+			log.Printf("skip %s: %v", oldURL, errRekey)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d objects failed, run again after fixing them", failed)
+	}
+	log.Println("done")
+}
+
+// collectLegacyURLs returns every banner url, trashed rows and revisions included, that is not under the new prefix yet
+func collectLegacyURLs(ctx context.Context, db *gorm.DB, publicURL string) ([]string, error) {
+	seen := map[string]bool{}
+	var legacyURLs []string
+
+	for _, table := range []interface{}{&model.Announcement{}, &model.AnnouncementRevision{}} {
+		for _, column := range imageColumns {
+			var urls []string
+			err := db.WithContext(ctx).Unscoped().Model(table).Distinct().Where(column+" <> ''").Pluck(column, &urls).Error
+			if err != nil {
+				return nil, err
+			}
+
+			for _, item := range urls {
+				key, ok := storage.KeyFromURL(publicURL, item)
+				if seen[item] || !ok || strings.HasPrefix(key, storage.PrefixAnnouncements+"/") {
+					continue
+				}
+				seen[item] = true
+				legacyURLs = append(legacyURLs, item)
+			}
+		}
+	}
+
+	return legacyURLs, nil
+}
+
+func rekey(ctx context.Context, db *gorm.DB, client *s3.Client, cfg config.AWSConfig, oldURL string, dryRun bool) error {
+	oldKey, _ := storage.KeyFromURL(cfg.PublicURL, oldURL)
+
+	object, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(cfg.Bucket),
+		Key:    aws.String(oldKey),
+	})
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadAll(object.Body)
+	object.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	// objects stored after the variants change keep their variant name, older ones had a single size
+	extension := strings.ToLower(strings.TrimPrefix(path.Ext(oldKey), "."))
+	if extension == "" || extension == "jpeg" {
+		extension = "jpg"
+	}
+	variant := ""
+	for _, name := range []string{imaging.VariantThumbnail, imaging.VariantMedium, imaging.VariantLarge} {
+		if strings.HasSuffix(strings.TrimSuffix(oldKey, path.Ext(oldKey)), "-"+name) {
+			variant = name
+		}
+	}
+
+	newKey := storage.ContentKey(storage.PrefixAnnouncements, content, variant, extension)
+	newURL := cfg.PublicURL + "/" + newKey
+	log.Printf("%s -> %s", oldKey, newKey)
+	if dryRun {
+		return nil
+	}
+
+	_, err = client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(cfg.Bucket),
+		CopySource: aws.String(cfg.Bucket + "/" + url.PathEscape(oldKey)),
+		Key:        aws.String(newKey),
+		ACL:        "public-read",
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []interface{}{&model.Announcement{}, &model.AnnouncementRevision{}} {
+			for _, column := range imageColumns {
+				errUpdate := tx.Unscoped().Model(table).Where(column+" = ?", oldURL).Update(column, newURL).Error
+				if errUpdate != nil {
+					return errUpdate
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the rows no longer point at the old key, losing it now is safe
+	_, err = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(cfg.Bucket),
+		Key:    aws.String(oldKey),
+	})
+	return err
+}
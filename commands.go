@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"log"
+	"nurul-iman-blok-m/audit"
+	"nurul-iman-blok-m/config"
+	"nurul-iman-blok-m/database"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/seed"
+	"os"
+	"strings"
+)
+
+// runSeed creates the default roles, demo content is added in development or with -demo
+func runSeed(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	demo := flags.Bool("demo", false, "also add demo accounts and content")
+	flags.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db := database.Db(cfg.Database)
+
+	created, err := seed.Roles(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d roles created\n", created)
+
+	if !*demo && cfg.App.Env != config.EnvDevelopment {
+		return
+	}
+	added, err := seed.Demo(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !added {
+		fmt.Println("demo content already present")
+		return
+	}
+	fmt.Printf("demo content added, log in as takmir@demo.local, ustadz@demo.local or jamaah@demo.local with password %s\n", seed.DemoPassword)
+}
+
+func runCreateAdmin(args []string) {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := flags.String("email", "", "email address of the account")
+	name := flags.String("name", "Administrator", "name of a new account")
+	flags.Parse(args)
+
+	if !strings.Contains(*email, "@") {
+		log.Fatal("create-admin needs -email, e.g. create-admin -email takmir@example.org")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := database.Db(cfg.Database)
+	// the account as it was, empty when create-admin makes a new one
+	var before model.User
+	err = db.Where("email = ?", strings.TrimSpace(*email)).Find(&before).Error
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	user, password, err := seed.CreateAdmin(db, strings.TrimSpace(*email), *name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the role change bypasses the api, the log shows the command as its actor
+	input := audit.AuditInput{ActorName: "create-admin command", Action: audit.ActionCreate, EntityType: audit.EntityUser, EntityID: user.ID, After: user}
+	if before.ID != 0 {
+		input.Action = audit.ActionUpdate
+		input.Before = before
+	}
+	err = audit.NewService(audit.NewRepository(db)).Record(context.Background(), input)
+	if err != nil {
+		log.Printf("audit error: %v", err)
+	}
+	if password == "" {
+		fmt.Printf("%s is now super-admin\n", user.Email)
+		return
+	}
+	fmt.Printf("super-admin %s created with password %s\nchange it after the first login\n", user.Email, password)
+}
+
+// runRotateSecret prints a new API_SECRET and keeps the current one as API_SECRET_PREVIOUS,
+// so logged in users are not signed out. Remove API_SECRET_PREVIOUS once their tokens are gone
+func runRotateSecret(args []string) {
+	flags := flag.NewFlagSet("rotate-secret", flag.ExitOnError)
+	envFile := flags.String("env-file", "", "write the new values into this .env file")
+	flags.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	random := make([]byte, 32)
+	_, err = rand.Read(random)
+	if err != nil {
+		log.Fatal(err)
+	}
+	values := map[string]string{
+		"API_SECRET":          hex.EncodeToString(random),
+		"API_SECRET_PREVIOUS": cfg.App.Secret,
+	}
+
+	if *envFile == "" {
+		fmt.Printf("API_SECRET=%s\nAPI_SECRET_PREVIOUS=%s\n", values["API_SECRET"], values["API_SECRET_PREVIOUS"])
+		return
+	}
+
+	existing, err := godotenv.Read(*envFile)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for key, value := range values {
+		existing[key] = value
+	}
+
+	err = godotenv.Write(existing, *envFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("API_SECRET rotated in %s, restart the api to use it\n", *envFile)
+}
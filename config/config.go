@@ -0,0 +1,177 @@
+package config
+
+const (
+	EnvProduction  = "production"
+	EnvDevelopment = "development"
+)
+
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Config is every setting of the api. Values come from, in increasing priority: the defaults below,
+// the YAML file named by CONFIG_FILE (config.yaml when present), .env and the process environment
+type Config struct {
+	App       AppConfig       `yaml:"app"`
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	AWS       AWSConfig       `yaml:"aws"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Trash     TrashConfig     `yaml:"trash"`
+	Podcast   PodcastConfig   `yaml:"podcast"`
+	Push      PushConfig      `yaml:"push"`
+	Mail      MailConfig      `yaml:"mail"`
+	Broadcast BroadcastConfig `yaml:"broadcast"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
+
+// AppConfig.Env is production or development, development applies pending migrations on start
+type AppConfig struct {
+	Env     string `yaml:"env" env:"APP_ENV"`
+	URL     string `yaml:"url" env:"APP_URL"`
+	SiteURL string `yaml:"site_url" env:"SITE_URL"`
+	Port    int    `yaml:"port" env:"PORT"`
+	Secret  string `yaml:"secret" env:"API_SECRET"`
+	// PreviousSecret still validates tokens signed before the last rotate-secret
+	PreviousSecret string `yaml:"previous_secret" env:"API_SECRET_PREVIOUS"`
+}
+
+// ServerConfig timeouts are in seconds. Heroku kills the process 30 seconds after SIGTERM,
+// so in-flight requests get ShutdownTimeout to finish before that
+type ServerConfig struct {
+	ReadTimeout     int `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout    int `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout     int `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout int `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+}
+
+// DatabaseConfig.Driver picks postgres, mysql or sqlite. SQLite only needs Path, use ":memory:"
+// for a throwaway database. Port defaults to the usual port of the driver
+type DatabaseConfig struct {
+	Driver   string `yaml:"driver" env:"DB_DRIVER"`
+	Path     string `yaml:"path" env:"DB_PATH"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE"`
+	TimeZone string `yaml:"timezone" env:"DB_TIMEZONE"`
+}
+
+// AWSConfig without keys falls back to the default credential chain, e.g. an instance role
+type AWSConfig struct {
+	Region string `yaml:"region" env:"AWS_REGION"`
+	Bucket string `yaml:"bucket" env:"AWS_BUCKET"`
+	// PublicURL is where objects of the bucket are served from, defaults to the bucket's own S3 endpoint
+	PublicURL       string `yaml:"public_url" env:"AWS_PUBLIC_URL"`
+	AccessKeyID     string `yaml:"access_key_id" env:"AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secret_access_key" env:"AWS_SECRET_ACCESS_KEY"`
+}
+
+type StorageConfig struct {
+	Driver   string `yaml:"driver" env:"STORAGE_DRIVER"`
+	LocalDir string `yaml:"local_dir" env:"STORAGE_LOCAL_DIR"`
+}
+
+type TrashConfig struct {
+	RetentionDays int `yaml:"retention_days" env:"TRASH_RETENTION_DAYS"`
+}
+
+type PodcastConfig struct {
+	OwnerEmail string `yaml:"owner_email" env:"PODCAST_OWNER_EMAIL"`
+	ImageURL   string `yaml:"image_url" env:"PODCAST_IMAGE_URL"`
+}
+
+type PushConfig struct {
+	FCMCredentialsFile string     `yaml:"fcm_credentials_file" env:"FCM_CREDENTIALS_FILE"`
+	APNS               APNSConfig `yaml:"apns"`
+}
+
+type APNSConfig struct {
+	KeyFile    string `yaml:"key_file" env:"APNS_KEY_FILE"`
+	KeyID      string `yaml:"key_id" env:"APNS_KEY_ID"`
+	TeamID     string `yaml:"team_id" env:"APNS_TEAM_ID"`
+	Topic      string `yaml:"topic" env:"APNS_TOPIC"`
+	Production bool   `yaml:"production" env:"APNS_PRODUCTION"`
+}
+
+type MailConfig struct {
+	Driver string     `yaml:"driver" env:"MAIL_DRIVER"`
+	From   string     `yaml:"from" env:"MAIL_FROM"`
+	Dir    string     `yaml:"dir" env:"MAIL_DIR"`
+	SMTP   SMTPConfig `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+}
+
+type BroadcastConfig struct {
+	Target string `yaml:"target" env:"BROADCAST_TARGET"`
+}
+
+// MetricsConfig.Token protects /metrics, Prometheus sends it as bearer token. Empty leaves it open
+type MetricsConfig struct {
+	Token string `yaml:"token" env:"METRICS_TOKEN"`
+}
+
+// TracingConfig.Exporter is none, stdout or otlp. The OTLP endpoint comes from the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT variable, e.g. http://localhost:4318
+type TracingConfig struct {
+	Exporter      string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName   string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SamplePercent int    `yaml:"sample_percent" env:"TRACING_SAMPLE_PERCENT"`
+}
+
+func defaults() Config {
+	return Config{
+		App: AppConfig{
+			Env:  EnvProduction,
+			URL:  "http://localhost:8080",
+			Port: 8080,
+		},
+		Server: ServerConfig{
+			ReadTimeout:     60,
+			WriteTimeout:    60,
+			IdleTimeout:     120,
+			ShutdownTimeout: 25,
+		},
+		Database: DatabaseConfig{
+			Driver:   DriverPostgres,
+			Path:     "nurul-iman.db",
+			SSLMode:  "require",
+			TimeZone: "Asia/Jakarta",
+		},
+		Storage: StorageConfig{
+			Driver:   "s3",
+			LocalDir: "./uploads",
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
+		},
+		Mail: MailConfig{
+			Driver: "file",
+			From:   "Masjid Nurul Iman Blok M <no-reply@localhost>",
+			Dir:    "./mail",
+			SMTP:   SMTPConfig{Port: 587},
+		},
+		Tracing: TracingConfig{
+			Exporter:      TracingNone,
+			ServiceName:   "nurul-iman-blok-m",
+			SamplePercent: 100,
+		},
+	}
+}
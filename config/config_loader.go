@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const defaultFile = "config.yaml"
+
+// Load reads the configuration and validates it, the error lists every problem found
+func Load() (Config, error) {
+	cfg := defaults()
+
+	// .env only fills variables that are not set in the environment already
+	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("config: .env: %w", err)
+	}
+
+	err = loadFile(&cfg)
+	if err != nil {
+		return cfg, err
+	}
+
+	var problems ValidationError
+	applyEnv(reflect.ValueOf(&cfg).Elem(), &problems)
+
+	if cfg.Database.Port == 0 {
+		cfg.Database.Port = defaultPorts[cfg.Database.Driver]
+	}
+	if cfg.App.SiteURL == "" {
+		cfg.App.SiteURL = cfg.App.URL
+	}
+	if cfg.AWS.PublicURL == "" && cfg.AWS.Bucket != "" && cfg.AWS.Region != "" {
+		cfg.AWS.PublicURL = "https://" + cfg.AWS.Bucket + ".s3." + cfg.AWS.Region + ".amazonaws.com"
+	}
+	cfg.App.URL = strings.TrimRight(cfg.App.URL, "/")
+	cfg.App.SiteURL = strings.TrimRight(cfg.App.SiteURL, "/")
+	cfg.AWS.PublicURL = strings.TrimRight(cfg.AWS.PublicURL, "/")
+
+	var invalid ValidationError
+	if errors.As(cfg.Validate(), &invalid) {
+		problems = append(problems, invalid...)
+	}
+	if len(problems) > 0 {
+		return cfg, problems
+	}
+	return cfg, nil
+}
+
+// loadFile reads CONFIG_FILE, a missing config.yaml is fine but a missing CONFIG_FILE is not
+func loadFile(cfg *Config) error {
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit || path == "" {
+		path = defaultFile
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	err = yaml.UnmarshalStrict(content, cfg)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides every field tagged with env when that variable is set and not empty
+func applyEnv(value reflect.Value, problems *ValidationError) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			applyEnv(field, problems)
+			continue
+		}
+
+		name := value.Type().Field(i).Tag.Get("env")
+		raw := strings.TrimSpace(os.Getenv(name))
+		if name == "" || raw == "" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(raw)
+		case reflect.Int:
+			number, err := strconv.Atoi(raw)
+			if err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s must be a whole number, got %q", name, raw))
+				continue
+			}
+			field.SetInt(int64(number))
+		case reflect.Bool:
+			flag, err := strconv.ParseBool(raw)
+			if err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s must be true or false, got %q", name, raw))
+				continue
+			}
+			field.SetBool(flag)
+		}
+	}
+}
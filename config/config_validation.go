@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// ValidationError lists every invalid setting, so a deploy can be fixed in one go
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "config: invalid configuration:\n  - " + strings.Join(e, "\n  - ")
+}
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+var defaultPorts = map[string]int{
+	DriverPostgres: 5432,
+	DriverMySQL:    3306,
+}
+
+func (c Config) Validate() error {
+	var problems ValidationError
+	require := func(value string, name string) {
+		if strings.TrimSpace(value) == "" {
+			problems = append(problems, name+" is required")
+		}
+	}
+	absoluteURL := func(value string, name string) {
+		parsed, err := url.Parse(value)
+		if value != "" && (err != nil || parsed.Scheme == "" || parsed.Host == "") {
+			problems = append(problems, fmt.Sprintf("%s must be an absolute url, got %q", name, value))
+		}
+	}
+	oneOf := func(value string, name string, allowed ...string) {
+		for _, item := range allowed {
+			if value == item {
+				return
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value))
+	}
+	positive := func(value int, name string) {
+		if value <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be positive, got %d", name, value))
+		}
+	}
+	port := func(value int, name string) {
+		if value <= 0 || value > 65535 {
+			problems = append(problems, fmt.Sprintf("%s must be a port number, got %d", name, value))
+		}
+	}
+
+	oneOf(c.App.Env, "APP_ENV", EnvProduction, EnvDevelopment)
+	require(c.App.URL, "APP_URL")
+	absoluteURL(c.App.URL, "APP_URL")
+	absoluteURL(c.App.SiteURL, "SITE_URL")
+	port(c.App.Port, "PORT")
+	require(c.App.Secret, "API_SECRET")
+
+	positive(c.Server.ReadTimeout, "HTTP_READ_TIMEOUT")
+	positive(c.Server.WriteTimeout, "HTTP_WRITE_TIMEOUT")
+	positive(c.Server.IdleTimeout, "HTTP_IDLE_TIMEOUT")
+	positive(c.Server.ShutdownTimeout, "HTTP_SHUTDOWN_TIMEOUT")
+
+	oneOf(c.Database.Driver, "DB_DRIVER", DriverPostgres, DriverMySQL, DriverSQLite)
+	if c.Database.Driver == DriverSQLite {
+		require(c.Database.Path, "DB_PATH")
+	} else {
+		require(c.Database.Host, "DB_HOST")
+		port(c.Database.Port, "DB_PORT")
+		require(c.Database.User, "DB_USER")
+		require(c.Database.Name, "DB_NAME")
+	}
+	if c.Database.Driver == DriverPostgres {
+		oneOf(c.Database.SSLMode, "DB_SSLMODE", sslModes...)
+	}
+
+	oneOf(c.Storage.Driver, "STORAGE_DRIVER", "s3", "local")
+	if c.Storage.Driver == "s3" {
+		require(c.AWS.Region, "AWS_REGION")
+		require(c.AWS.Bucket, "AWS_BUCKET")
+	}
+	absoluteURL(c.AWS.PublicURL, "AWS_PUBLIC_URL")
+	if (c.AWS.AccessKeyID == "") != (c.AWS.SecretAccessKey == "") {
+		problems = append(problems, "AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set together")
+	}
+	if c.Storage.Driver == "local" {
+		require(c.Storage.LocalDir, "STORAGE_LOCAL_DIR")
+	}
+
+	positive(c.Trash.RetentionDays, "TRASH_RETENTION_DAYS")
+
+	oneOf(c.Tracing.Exporter, "TRACING_EXPORTER", TracingNone, TracingStdout, TracingOTLP)
+	require(c.Tracing.ServiceName, "OTEL_SERVICE_NAME")
+	if c.Tracing.SamplePercent < 0 || c.Tracing.SamplePercent > 100 {
+		problems = append(problems, fmt.Sprintf("TRACING_SAMPLE_PERCENT must be between 0 and 100, got %d", c.Tracing.SamplePercent))
+	}
+	absoluteURL(c.Podcast.ImageURL, "PODCAST_IMAGE_URL")
+
+	if c.Push.APNS.KeyFile != "" {
+		require(c.Push.APNS.KeyID, "APNS_KEY_ID")
+		require(c.Push.APNS.TeamID, "APNS_TEAM_ID")
+		require(c.Push.APNS.Topic, "APNS_TOPIC")
+	}
+
+	oneOf(c.Mail.Driver, "MAIL_DRIVER", "file", "smtp")
+	_, errFrom := mail.ParseAddress(c.Mail.From)
+	if errFrom != nil {
+		problems = append(problems, fmt.Sprintf("MAIL_FROM must be an email address, got %q", c.Mail.From))
+	}
+	switch c.Mail.Driver {
+	case "file":
+		require(c.Mail.Dir, "MAIL_DIR")
+	case "smtp":
+		require(c.Mail.SMTP.Host, "SMTP_HOST")
+		port(c.Mail.SMTP.Port, "SMTP_PORT")
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
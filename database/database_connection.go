@@ -1,37 +1,82 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log"
-	"nurul-iman-blok-m/model"
-	"os"
+	"net/url"
+	"nurul-iman-blok-m/config"
 )
 
-func Db() *gorm.DB {
-	//errEnv := godotenv.Load()
-	//if errEnv != nil {
-	//	log.Fatal(errEnv.Error())
-	//}
-
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=require TimeZone=Asia/Jakarta", dbHost, dbUser, dbPass, dbName, dbPort)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Db opens the connection, the schema is managed by the migration package
+func Db(cfg config.DatabaseConfig) *gorm.DB {
+	db, err := Open(cfg)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
-	errMigrate := db.AutoMigrate(&model.User{}, &model.Role{}, &model.Announcement{}, &model.Article{}, &model.Category{}, &model.StudyRundown{}, &model.StudyVideo{})
-	if errMigrate != nil {
-		log.Fatal(errMigrate.Error())
+	fmt.Printf("database connected (%s)\n", cfg.Driver)
+
+	return db
+}
+
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := dialectorFor(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println("database connected")
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
 
-	return db
+	if cfg.Driver == config.DriverSQLite && cfg.Path == ":memory:" {
+		// every connection would get its own empty in-memory database
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
+
+func dialectorFor(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s", cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode, cfg.TimeZone)
+		return postgres.Open(dsn), nil
+	case config.DriverMySQL:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=true&loc=%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name, url.QueryEscape(cfg.TimeZone))
+		return mysql.Open(dsn), nil
+	case config.DriverSQLite:
+		// foreign keys are off by default in SQLite, the busy timeout lets the background jobs wait for a write lock
+		return sqlite.Open(cfg.Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), nil
+	}
+
+	return nil, fmt.Errorf("database: unknown driver %q", cfg.Driver)
+}
+
+// Ping checks the connection pool can still reach the database
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the connection pool on shutdown
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
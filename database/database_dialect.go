@@ -0,0 +1,47 @@
+package database
+
+import (
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// Contains matches rows whose column contains search, ignoring case on every driver.
+// Postgres has ILIKE, MySQL and SQLite fall back to comparing lowercased values
+func Contains(column string, search string) func(db *gorm.DB) *gorm.DB {
+	pattern := "%" + escapeLike(search) + "%"
+	return func(db *gorm.DB) *gorm.DB {
+		switch db.Dialector.Name() {
+		case "postgres":
+			return db.Where(column+" ILIKE ? ESCAPE '\\'", pattern)
+		case "mysql":
+			// backslash is already the escape character, and '\' would open a string literal in MySQL
+			return db.Where("LOWER("+column+") LIKE ?", strings.ToLower(pattern))
+		}
+		return db.Where("LOWER("+column+") LIKE ? ESCAPE '\\'", strings.ToLower(pattern))
+	}
+}
+
+func escapeLike(search string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(search)
+}
+
+// IsDuplicateKey reports whether err is a unique constraint violation, on any of the drivers
+func IsDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062
+	}
+	// pgconn.PgError and the sqlite driver error only expose their codes through methods
+	var postgresErr interface{ SQLState() string }
+	if errors.As(err, &postgresErr) {
+		return postgresErr.SQLState() == "23505"
+	}
+	var sqliteErr interface{ Code() int }
+	if errors.As(err, &sqliteErr) {
+		// SQLITE_CONSTRAINT_UNIQUE, a primary key clash is SQLITE_CONSTRAINT_PRIMARYKEY
+		return sqliteErr.Code() == 2067
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
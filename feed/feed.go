@@ -0,0 +1,54 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Channel is the format independent feed, RSS, Atom and JSON Feed are rendered from it
+type Channel struct {
+	Title       string
+	Link        string
+	FeedURL     string
+	Description string
+	Language    string
+	Author      string
+	Updated     time.Time
+	Items       []Item
+}
+
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+	Enclosure *Enclosure
+}
+
+// Enclosure is a file attached to an item, Length is 0 when the size is not known
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// ETag is a strong validator derived from the rendered feed
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// LastModified is the newest update of the channel or any of its items
+func LastModified(channel Channel) time.Time {
+	last := channel.Updated
+	for _, item := range channel.Items {
+		if item.Updated.After(last) {
+			last = item.Updated
+		}
+	}
+	return last
+}
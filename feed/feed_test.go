@@ -0,0 +1,182 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var published = time.Date(2026, 10, 16, 5, 0, 0, 0, time.UTC)
+
+var channel = Channel{
+	Title:       "Pengumuman Masjid",
+	Link:        "https://example.org/announcements",
+	FeedURL:     "https://example.org/feeds/announcements.xml",
+	Description: "Pengumuman terbaru",
+	Language:    "id",
+	Author:      "Takmir",
+	Updated:     published,
+	Items: []Item{
+		{
+			ID:        "urn:nurul-iman:announcement:2",
+			Title:     "Kajian Ahad <Pagi> & Dzikir",
+			Link:      "https://example.org/announcements/kajian-ahad",
+			Summary:   "Bersama ustadz",
+			Author:    "Ustadz",
+			Published: published,
+			Updated:   published.Add(2 * time.Hour),
+			Enclosure: &Enclosure{URL: "https://cdn.example.org/banner.jpg", Type: "image/jpeg", Length: 2048},
+		},
+		{
+			ID:        "urn:nurul-iman:announcement:1",
+			Title:     "Kerja bakti",
+			Link:      "https://example.org/announcements/kerja-bakti",
+			Published: published.Add(-24 * time.Hour),
+			Updated:   published.Add(-24 * time.Hour),
+		},
+	},
+}
+
+func TestRSS(t *testing.T) {
+	body, err := RSS(channel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(body), xml.Header) {
+		t.Error("missing xml header")
+	}
+
+	var doc rss
+	err = xml.Unmarshal(body, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Channel.Items) != 2 {
+		t.Fatalf("got %d items", len(doc.Channel.Items))
+	}
+	item := doc.Channel.Items[0]
+	if item.GUID.Value != "urn:nurul-iman:announcement:2" || item.GUID.IsPermaLink != "false" {
+		t.Errorf("got guid %+v", item.GUID)
+	}
+	if item.Title != "Kajian Ahad <Pagi> & Dzikir" {
+		t.Errorf("title not escaped and kept, got %q", item.Title)
+	}
+	if item.PubDate != "Fri, 16 Oct 2026 05:00:00 +0000" {
+		t.Errorf("got pubDate %q", item.PubDate)
+	}
+	if item.Enclosure == nil || item.Enclosure.Length != "2048" || item.Enclosure.Type != "image/jpeg" {
+		t.Errorf("got enclosure %+v", item.Enclosure)
+	}
+	if doc.Channel.Items[1].Enclosure != nil {
+		t.Error("item without banner got an enclosure")
+	}
+	// the build date follows the newest item update
+	if doc.Channel.LastBuildDate != "Fri, 16 Oct 2026 07:00:00 +0000" {
+		t.Errorf("got lastBuildDate %q", doc.Channel.LastBuildDate)
+	}
+}
+
+func TestAtom(t *testing.T) {
+	body, err := Atom(channel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc atomFeed
+	err = xml.Unmarshal(body, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != channel.FeedURL || doc.Updated != "2026-10-16T07:00:00Z" {
+		t.Errorf("got id %q, updated %q", doc.ID, doc.Updated)
+	}
+	if doc.Author == nil || doc.Author.Name != "Takmir" {
+		t.Errorf("got author %+v", doc.Author)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("got %d entries", len(doc.Entries))
+	}
+
+	entry := doc.Entries[0]
+	if entry.ID != "urn:nurul-iman:announcement:2" || entry.Published != "2026-10-16T05:00:00Z" {
+		t.Errorf("got entry %+v", entry)
+	}
+	var enclosure *atomLink
+	for i := range entry.Links {
+		if entry.Links[i].Rel == "enclosure" {
+			enclosure = &entry.Links[i]
+		}
+	}
+	if enclosure == nil || enclosure.Href != "https://cdn.example.org/banner.jpg" || enclosure.Length != "2048" {
+		t.Errorf("got enclosure %+v", enclosure)
+	}
+	if doc.Entries[1].Author != nil {
+		t.Error("entry without author got one")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	body, err := JSON(channel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc jsonFeed
+	err = json.Unmarshal(body, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || len(doc.Items) != 2 {
+		t.Fatalf("got version %q with %d items", doc.Version, len(doc.Items))
+	}
+
+	item := doc.Items[0]
+	if item.Image != "https://cdn.example.org/banner.jpg" || len(item.Attachments) != 1 || item.Attachments[0].SizeInBytes != 2048 {
+		t.Errorf("got image %q, attachments %+v", item.Image, item.Attachments)
+	}
+	if len(item.Authors) != 1 || item.Authors[0].Name != "Ustadz" {
+		t.Errorf("got authors %+v", item.Authors)
+	}
+
+	empty, err := JSON(Channel{Title: "Kosong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(empty), `"items": []`) {
+		t.Errorf("empty feed must keep an items array, got %s", empty)
+	}
+}
+
+func TestETag(t *testing.T) {
+	first := ETag([]byte("feed"))
+	if first != ETag([]byte("feed")) {
+		t.Error("etag is not stable")
+	}
+	if first == ETag([]byte("feed2")) {
+		t.Error("etag ignores the body")
+	}
+	if len(first) != 34 || first[0] != '"' || first[len(first)-1] != '"' {
+		t.Errorf("etag must be a quoted 32 hex string, got %s", first)
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel Channel
+		want    time.Time
+	}{
+		{"no items", Channel{Updated: published}, published},
+		{"newer item", channel, published.Add(2 * time.Hour)},
+		{"older items", Channel{Updated: published, Items: []Item{{Updated: published.Add(-time.Hour)}}}, published},
+		{"zero channel time", Channel{Items: []Item{{Updated: published}}}, published},
+	}
+
+	for _, test := range tests {
+		if got := LastModified(test.channel); !got.Equal(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package feed
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const JSONContentType = "application/feed+json; charset=utf-8"
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentText   string               `json:"content_text"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+// JSON renders a JSON Feed 1.1 document
+func JSON(channel Channel) ([]byte, error) {
+	out := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       channel.Title,
+		HomePageURL: channel.Link,
+		FeedURL:     channel.FeedURL,
+		Description: channel.Description,
+		Language:    channel.Language,
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, item := range channel.Items {
+		jsonItem := jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentText:   item.Summary,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+		}
+		if item.Author != "" {
+			jsonItem.Authors = []jsonFeedAuthor{{Name: item.Author}}
+		}
+		if item.Enclosure != nil {
+			jsonItem.Image = item.Enclosure.URL
+			jsonItem.Attachments = []jsonFeedAttachment{{
+				URL:         item.Enclosure.URL,
+				MimeType:    item.Enclosure.Type,
+				SizeInBytes: item.Enclosure.Length,
+			}}
+		}
+		out.Items = append(out.Items, jsonItem)
+	}
+
+	return json.MarshalIndent(out, "", "  ")
+}
@@ -0,0 +1,89 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+const RSSContentType = "application/rss+xml; charset=utf-8"
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          rssLink   `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description"`
+	Author      string        `xml:"author,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+func RSS(channel Channel) ([]byte, error) {
+	out := rssChannel{
+		Title:         channel.Title,
+		Link:          channel.Link,
+		Self:          rssLink{Href: channel.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		Description:   channel.Description,
+		Language:      channel.Language,
+		LastBuildDate: LastModified(channel).Format(time.RFC1123Z),
+		Items:         []rssItem{},
+	}
+
+	for _, item := range channel.Items {
+		rssItem := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Summary,
+			GUID:        rssGUID{IsPermaLink: "false", Value: item.ID},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		}
+		if item.Enclosure != nil {
+			rssItem.Enclosure = &rssEnclosure{
+				URL:    item.Enclosure.URL,
+				Length: strconv.FormatInt(item.Enclosure.Length, 10),
+				Type:   item.Enclosure.Type,
+			}
+		}
+		out.Items = append(out.Items, rssItem)
+	}
+
+	body, err := xml.MarshalIndent(rss{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: out}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
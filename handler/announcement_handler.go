@@ -1,29 +1,35 @@
 package handler
 
 import (
+	"bytes"
 	"context"
-	"fmt"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"nurul-iman-blok-m/announcement"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
 	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/imaging"
+	"nurul-iman-blok-m/media"
+	"nurul-iman-blok-m/metrics"
 	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/role"
+	"nurul-iman-blok-m/storage"
 	"strconv"
-	"strings"
-	"time"
 )
 
 type announcementHandler struct {
-	service  announcement.AnnouncementService
-	manager  manager.Uploader
-	s3Client s3.Client
+	service      announcement.AnnouncementService
+	storage      storage.Storage
+	mediaService media.MediaService
+	auditService audit.AuditService
 }
 
-func NewHandlerAnnouncement(service announcement.AnnouncementService, manager manager.Uploader, s3Client s3.Client) *announcementHandler {
-	return &announcementHandler{service, manager, s3Client}
+func NewHandlerAnnouncement(service announcement.AnnouncementService, storage storage.Storage, mediaService media.MediaService, auditService audit.AuditService) *announcementHandler {
+	return &announcementHandler{service, storage, mediaService, auditService}
 }
 
 func (h *announcementHandler) AddAnnouncement(c *gin.Context) {
@@ -31,71 +37,47 @@ func (h *announcementHandler) AddAnnouncement(c *gin.Context) {
 	err := c.ShouldBind(&input)
 
 	if err != nil {
-		errors := helper.FormatValidationError(err)
-		errMessage := gin.H{"errors": errors}
-
-		response := helper.ApiResponse("You must completed field", http.StatusUnprocessableEntity, "error", errMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperr.Input("You must completed field", err))
 		return
 	}
 	currentUser := c.MustGet("currentUser").(model.User)
 	input.UserID = currentUser.ID
 	input.User = currentUser
 
-	fileImage, errBanner := c.FormFile("banner")
-	if errBanner != nil {
-		fmt.Println("banner-error")
-		response := helper.ApiResponse("Failed to upload banner image", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionEditContent) {
+		c.Error(apperr.Forbidden("You not have access for add"))
 		return
 	}
 
-	if fileImage.Size > int64(1024000) {
-		response := helper.ApiResponse("Image too large, max 1MB", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
-		return
+	// without publish permission new announcements start as draft and go through review
+	if !role.Can(currentUser.Role.RoleName, role.PermissionPublish) {
+		if input.Status != "" && input.Status != announcement.StatusDraft {
+			c.Error(apperr.Forbidden("You not have access for publish, submit for review instead"))
+			return
+		}
+		input.Status = announcement.StatusDraft
 	}
-	f, openErr := fileImage.Open()
 
-	if openErr != nil {
-		response := helper.ApiResponse("Failed to upload image", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+	fileImage, _ := c.FormFile("banner")
+	if fileImage == nil && input.BannerMediaID == 0 {
+		c.Error(apperr.Validation("banner_required", "Failed to upload banner image"))
 		return
 	}
 
-	extenstionFile := ""
-	fileName := strings.Split(fileImage.Filename, ".")
-
-	if len(fileName) == 2 {
-		extenstionFile = fileName[1]
-	}
-	path := fmt.Sprintf("announcement-%s-%s.%s", input.Slug, time.Now().Format("2006-02-01"), extenstionFile)
-
-	result, errUploadBanner := h.manager.Upload(context.TODO(), &s3.PutObjectInput{
-		Bucket: aws.String("masjid-nurul-iman"),
-		Key:    aws.String(path),
-		Body:   f,
-		ACL:    "public-read",
-	})
-
+	banner, errUploadBanner := h.resolveBanner(c.Request.Context(), fileImage, input.BannerMediaID, currentUser.ID)
 	if errUploadBanner != nil {
-		response := helper.ApiResponse("Upload failed", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
-		return
-	}
-	if currentUser.Role.RoleName == "user" || currentUser.Role.RoleName == "ustadz" {
-		response := helper.ApiResponse("You not have access for add", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(uploadError(errUploadBanner))
 		return
 	}
 
-	responseAddAnnouncement, createdBy, errAdd := h.service.AddAnnouncement(input, result.Location)
+	responseAddAnnouncement, createdBy, errAdd := h.service.AddAnnouncement(c.Request.Context(), input, banner)
 	if errAdd != nil {
-		response := helper.ApiResponse("Failed to add announcement", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errAdd, "Failed to add announcement"))
 		return
 	}
 
+	recordAudit(h.auditService, c, audit.ActionCreate, audit.EntityAnnouncement, responseAddAnnouncement.ID, nil, responseAddAnnouncement)
+
 	formatter := announcement.AnnouncementFormat(responseAddAnnouncement, createdBy)
 
 	response := helper.ApiResponse("Success to add announcement", http.StatusOK, "success", formatter)
@@ -104,15 +86,23 @@ func (h *announcementHandler) AddAnnouncement(c *gin.Context) {
 }
 
 func (h *announcementHandler) GetAllAnnouncement(c *gin.Context) {
+	var input announcement.AnnouncementListInput
+	errInput := c.ShouldBindQuery(&input)
+	if errInput != nil {
+		c.Error(apperr.Input("Invalid filter", errInput))
+		return
+	}
+	// visitors only see what is live right now, editors see every state
+	input.PublishedOnly = !isEditor(c)
+
 	page := c.Request.URL.Query().Get("page")
 	perPage := c.Request.URL.Query().Get("per_page")
 
 	paginate := helper.PaginateList(page, perPage)
 
-	announcements, count, err := h.service.GetListAnnouncement(paginate)
+	announcements, count, err := h.service.GetListAnnouncement(c.Request.Context(), input, paginate)
 	if err != nil {
-		response := helper.ApiResponse("Error to get announcements", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(err, "Error to get announcements"))
 		return
 	}
 
@@ -127,15 +117,41 @@ func (h *announcementHandler) GetDetailAnnouncement(c *gin.Context) {
 	var input announcement.AnnouncementDetailInput
 	err := c.ShouldBindUri(&input)
 	if err != nil {
-		response := helper.ApiResponse("Announcement detail not found", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Announcement detail not found"))
+		return
+	}
+
+	announcementDetail, errDetail := h.service.GetDetailAnnouncement(c.Request.Context(), input)
+	if errDetail != nil {
+		c.Error(apperr.Wrap(errDetail, "Failed to get detail announcement"))
+		return
+	}
+
+	if !isEditor(c) && !announcement.IsPublic(announcementDetail) {
+		c.Error(apperr.NotFound("announcement_not_found", "Announcement detail not found"))
+		return
+	}
+
+	response := helper.ApiResponse("Announcement Detail", http.StatusOK, "success", announcement.AnnouncementListFormat(announcementDetail))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *announcementHandler) GetDetailAnnouncementBySlug(c *gin.Context) {
+	var input announcement.AnnouncementSlugInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Announcement detail not found"))
 		return
 	}
 
-	announcementDetail, errDetail := h.service.GetDetailAnnouncement(input)
+	announcementDetail, errDetail := h.service.GetDetailAnnouncementBySlug(c.Request.Context(), input)
 	if errDetail != nil {
-		response := helper.ApiResponse("Failed to get detail announcement", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errDetail, "Failed to get detail announcement"))
+		return
+	}
+
+	if !isEditor(c) && !announcement.IsPublic(announcementDetail) {
+		c.Error(apperr.NotFound("announcement_not_found", "Announcement detail not found"))
 		return
 	}
 
@@ -147,22 +163,21 @@ func (h *announcementHandler) DeleteAnnouncement(c *gin.Context) {
 	var input announcement.AnnouncementDetailInput
 	err := c.ShouldBindUri(&input)
 	if err != nil {
-		response := helper.ApiResponse("Delete Failed", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Input("Delete Failed", err))
 		return
 	}
 	currentUser := c.MustGet("currentUser").(model.User)
-	if currentUser.Role.RoleName == "admin" {
-		response := helper.ApiResponse("You not have access for delete", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionEditContent) {
+		c.Error(apperr.Forbidden("You not have access for delete"))
 		return
 	}
-	errDelete := h.service.DeleteAnnouncement(input)
+	before, _ := h.service.GetDetailAnnouncement(c.Request.Context(), input)
+	errDelete := h.service.DeleteAnnouncement(c.Request.Context(), input, role.Can(currentUser.Role.RoleName, role.PermissionPublish))
 	if errDelete != nil {
-		response := helper.ApiResponse("Delete failed", http.StatusBadRequest, "error", errDelete)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errDelete, "Delete failed"))
 		return
 	}
+	recordAudit(h.auditService, c, audit.ActionDelete, audit.EntityAnnouncement, input.ID, before, nil)
 	response := helper.ApiResponse("Delete Success", http.StatusOK, "Success", nil)
 	c.JSON(http.StatusOK, response)
 }
@@ -171,8 +186,7 @@ func (h *announcementHandler) UpdateAnnouncement(c *gin.Context) {
 	var inputID announcement.AnnouncementDetailInput
 	err := c.ShouldBindUri(&inputID)
 	if err != nil {
-		response := helper.ApiResponse("Failed To Update because ID not found", http.StatusBadRequest, "Error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Failed To Update because ID not found"))
 		return
 	}
 
@@ -180,83 +194,54 @@ func (h *announcementHandler) UpdateAnnouncement(c *gin.Context) {
 	errInputUpdate := c.ShouldBind(&inputUpdate)
 
 	if errInputUpdate != nil {
-		errors := helper.FormatValidationError(err)
-		errMessage := gin.H{"errors": errors}
-
-		response := helper.ApiResponse("You must completed field", http.StatusUnprocessableEntity, "error", errMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperr.Input("You must completed field", errInputUpdate))
 		return
 	}
 
 	fileImage, _ := c.FormFile("banner")
 	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionEditContent) {
+		c.Error(apperr.Forbidden("You not have access for update"))
+		return
+	}
+	inputUpdate.UserID = currentUser.ID
+	inputUpdate.CanPublish = role.Can(currentUser.Role.RoleName, role.PermissionPublish)
 
-	if fileImage != nil {
-		if fileImage.Size > int64(1024000) {
-			response := helper.ApiResponse("Image too large, max 1MB", http.StatusBadRequest, "error", nil)
-			c.JSON(http.StatusBadRequest, response)
-			return
-		}
-		f, openErr := fileImage.Open()
-
-		if openErr != nil {
-			response := helper.ApiResponse("Failed to upload image", http.StatusBadRequest, "error", nil)
-			c.JSON(http.StatusBadRequest, response)
-			return
-		}
-
-		extenstionFile := ""
-		fileName := strings.Split(fileImage.Filename, ".")
-
-		if len(fileName) == 2 {
-			extenstionFile = fileName[1]
-		}
-		path := fmt.Sprintf("announcement-update-%s.%s", time.Now().Format("2006-02-01"), extenstionFile)
-
-		result, errUploadBanner := h.manager.Upload(context.TODO(), &s3.PutObjectInput{
-			Bucket: aws.String("masjid-nurul-iman"),
-			Key:    aws.String(path),
-			Body:   f,
-			ACL:    "public-read",
-		})
+	if !inputUpdate.CanPublish && inputUpdate.Status != "" && inputUpdate.Status != announcement.StatusDraft {
+		c.Error(apperr.Forbidden("You not have access for publish, submit for review instead"))
+		return
+	}
+	before, _ := h.service.GetDetailAnnouncement(c.Request.Context(), inputID)
 
+	if fileImage != nil || inputUpdate.BannerMediaID != 0 {
+		banner, errUploadBanner := h.resolveBanner(c.Request.Context(), fileImage, inputUpdate.BannerMediaID, currentUser.ID)
 		if errUploadBanner != nil {
-			response := helper.ApiResponse("Upload failed", http.StatusBadRequest, "error", nil)
-			c.JSON(http.StatusBadRequest, response)
+			c.Error(uploadError(errUploadBanner))
 			return
 		}
 
-		if currentUser.Role.RoleName == "admin" {
-			response := helper.ApiResponse("You not have access for update", http.StatusBadRequest, "error", nil)
-			c.JSON(http.StatusBadRequest, response)
-			return
-		}
-
-		updateData, errUpdateData := h.service.UpdateAnnouncement(inputID, inputUpdate, result.Location, h.s3Client)
+		updateData, errUpdateData := h.service.UpdateAnnouncement(c.Request.Context(), inputID, inputUpdate, banner)
 		if errUpdateData != nil {
-			response := helper.ApiResponse("Failed to update announcement", http.StatusBadRequest, "error", nil)
-			c.JSON(http.StatusBadRequest, response)
+			c.Error(apperr.Wrap(errUpdateData, "Failed to update announcement"))
 			return
 		}
 
+		recordAudit(h.auditService, c, audit.ActionUpdate, audit.EntityAnnouncement, updateData.ID, before, updateData)
+
 		formatter := announcement.AnnouncementFormat(updateData, updateData.User.Name)
 
 		response := helper.ApiResponse("Success to update announcement", http.StatusOK, "success", formatter)
 
 		c.JSON(http.StatusOK, response)
 	} else {
-		if currentUser.Role.RoleName == "user" {
-			response := helper.ApiResponse("You not have access for update", http.StatusBadRequest, "error", nil)
-			c.JSON(http.StatusBadRequest, response)
-			return
-		}
-		updateData, errUpdateData := h.service.UpdateAnnouncement(inputID, inputUpdate, "", h.s3Client)
+		updateData, errUpdateData := h.service.UpdateAnnouncement(c.Request.Context(), inputID, inputUpdate, announcement.BannerInput{})
 		if errUpdateData != nil {
-			response := helper.ApiResponse("Failed to update announcement", http.StatusBadRequest, "error", nil)
-			c.JSON(http.StatusBadRequest, response)
+			c.Error(apperr.Wrap(errUpdateData, "Failed to update announcement"))
 			return
 		}
 
+		recordAudit(h.auditService, c, audit.ActionUpdate, audit.EntityAnnouncement, updateData.ID, before, updateData)
+
 		formatter := announcement.AnnouncementFormat(updateData, updateData.User.Name)
 
 		response := helper.ApiResponse("Success to update announcement", http.StatusOK, "success", formatter)
@@ -264,3 +249,231 @@ func (h *announcementHandler) UpdateAnnouncement(c *gin.Context) {
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+func (h *announcementHandler) GetRevisions(c *gin.Context) {
+	var input announcement.AnnouncementDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Announcement not found"))
+		return
+	}
+
+	revisions, errRevisions := h.service.GetRevisions(c.Request.Context(), input)
+	if errRevisions != nil {
+		c.Error(apperr.Wrap(errRevisions, "Failed to get revisions"))
+		return
+	}
+
+	response := helper.ApiResponse("List Revision", http.StatusOK, "success", announcement.AnnouncementRevisionsFormat(revisions))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *announcementHandler) DiffRevisions(c *gin.Context) {
+	var input announcement.AnnouncementDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Announcement not found"))
+		return
+	}
+
+	var diffInput announcement.AnnouncementRevisionDiffInput
+	errDiffInput := c.ShouldBindQuery(&diffInput)
+	if errDiffInput != nil {
+		c.Error(apperr.Input("You must completed field", errDiffInput))
+		return
+	}
+
+	diff, errDiff := h.service.DiffRevisions(c.Request.Context(), input, diffInput)
+	if errDiff != nil {
+		c.Error(apperr.Wrap(errDiff, "Failed to compare revisions"))
+		return
+	}
+
+	response := helper.ApiResponse("Revision Diff", http.StatusOK, "success", announcement.RevisionDiffFormat(diff))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *announcementHandler) RollbackRevision(c *gin.Context) {
+	var input announcement.AnnouncementRevisionInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Revision not found"))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionEditContent) {
+		c.Error(apperr.Forbidden("You not have access for update"))
+		return
+	}
+
+	before, _ := h.service.GetDetailAnnouncement(c.Request.Context(), announcement.AnnouncementDetailInput{ID: input.ID})
+	rollback, errRollback := h.service.RollbackRevision(c.Request.Context(), input, currentUser.ID, role.Can(currentUser.Role.RoleName, role.PermissionPublish))
+	if errRollback != nil {
+		c.Error(apperr.Wrap(errRollback, "Failed to rollback announcement"))
+		return
+	}
+	recordAudit(h.auditService, c, audit.ActionUpdate, audit.EntityAnnouncement, rollback.ID, before, rollback)
+
+	formatter := announcement.AnnouncementFormat(rollback, rollback.User.Name)
+
+	response := helper.ApiResponse("Success to rollback announcement", http.StatusOK, "success", formatter)
+	c.JSON(http.StatusOK, response)
+}
+
+// isEditor is true when the optional auth middleware found a logged in user allowed to manage content
+func isEditor(c *gin.Context) bool {
+	value, exists := c.Get("currentUser")
+	if !exists {
+		return false
+	}
+	currentUser, ok := value.(model.User)
+	return ok && role.Can(currentUser.Role.RoleName, role.PermissionEditContent)
+}
+
+// resolveBanner uploads the banner file, or picks the variants of an image already in the media library
+func (h *announcementHandler) resolveBanner(ctx context.Context, fileImage *multipart.FileHeader, mediaID uint, userID uint) (announcement.BannerInput, error) {
+	if fileImage != nil {
+		banner, err := h.uploadBanner(ctx, fileImage, storage.PrefixAnnouncements, userID)
+		if err != nil {
+			metrics.UploadFailed(metrics.UploadBanner, uploadFailureReason(err))
+			return banner, err
+		}
+		metrics.ObserveUpload(metrics.UploadBanner, fileImage.Size)
+		return banner, nil
+	}
+
+	asset, err := h.mediaService.GetAsset(ctx, media.MediaDetailInput{ID: mediaID})
+	if err != nil {
+		return announcement.BannerInput{}, errMediaNotFound
+	}
+	if !media.IsImage(asset) || asset.ParentID != nil {
+		return announcement.BannerInput{}, imaging.ErrUnsupportedType
+	}
+	if !processedBanner(asset) {
+		return announcement.BannerInput{}, errBannerNotProcessed
+	}
+
+	return announcement.BannerInput{
+		Large:     asset.URL,
+		Medium:    media.VariantURL(asset, imaging.VariantMedium),
+		Thumbnail: media.VariantURL(asset, imaging.VariantThumbnail),
+	}, nil
+}
+
+// uploadBanner resizes the image into its variants and uploads each of them.
+// Keys are derived from the original file content, uploading the same image twice reuses the stored objects.
+func (h *announcementHandler) uploadBanner(ctx context.Context, fileImage *multipart.FileHeader, prefix string, userID uint) (announcement.BannerInput, error) {
+	banner := announcement.BannerInput{}
+	if fileImage.Size > imaging.MaxUploadSize {
+		return banner, imaging.ErrTooLarge
+	}
+
+	f, openErr := fileImage.Open()
+	if openErr != nil {
+		return banner, openErr
+	}
+	defer f.Close()
+
+	content, errRead := io.ReadAll(io.LimitReader(f, imaging.MaxUploadSize+1))
+	if errRead != nil {
+		return banner, errRead
+	}
+
+	variants, errProcess := imaging.Process(bytes.NewReader(content))
+	if errProcess != nil {
+		return banner, errProcess
+	}
+
+	assets := map[string]media.AssetInput{}
+	for _, variant := range variants {
+		path := storage.ContentKey(prefix, content, variant.Name, variant.Extension)
+		assets[variant.Name] = media.AssetInput{
+			Key:         path,
+			URL:         h.storage.URL(path),
+			FileName:    fileImage.Filename,
+			ContentType: variant.ContentType,
+			Size:        int64(len(variant.Body)),
+			Width:       variant.Width,
+			Height:      variant.Height,
+			UserID:      userID,
+			Variant:     variant.Name,
+		}
+
+		_, errExists := h.storage.Stat(ctx, path)
+		if errExists == storage.ErrNotFound {
+			errExists = h.storage.Put(ctx, path, variant.ContentType, variant.Body)
+		}
+		if errExists != nil {
+			return banner, errExists
+		}
+
+		switch variant.Name {
+		case imaging.VariantLarge:
+			banner.Large = h.storage.URL(path)
+		case imaging.VariantMedium:
+			banner.Medium = h.storage.URL(path)
+		case imaging.VariantThumbnail:
+			banner.Thumbnail = h.storage.URL(path)
+		}
+	}
+
+	// the large variant is the library entry, the smaller ones hang below it
+	parent, errRegister := h.mediaService.RegisterAsset(ctx, assets[imaging.VariantLarge])
+	if errRegister != nil {
+		return banner, errRegister
+	}
+	for _, name := range []string{imaging.VariantMedium, imaging.VariantThumbnail} {
+		child := assets[name]
+		child.ParentID = &parent.ID
+		_, errRegister = h.mediaService.RegisterAsset(ctx, child)
+		if errRegister != nil {
+			return banner, errRegister
+		}
+	}
+
+	return banner, nil
+}
+
+var errMediaNotFound = errors.New("media not found")
+var errBannerNotProcessed = errors.New("media was not processed as a banner")
+
+// processedBanner is true for images that went through imaging.Process as a banner, their pixels were
+// re-encoded without metadata and the resized variants exist. Presigned uploads are stored as sent
+func processedBanner(asset model.MediaAsset) bool {
+	if asset.Variant != imaging.VariantLarge {
+		return false
+	}
+	found := map[string]bool{}
+	for _, variant := range asset.Variants {
+		found[variant.Variant] = true
+	}
+	return found[imaging.VariantMedium] && found[imaging.VariantThumbnail]
+}
+
+// uploadFailureReason keeps the upload_failures_total labels to a few values
+func uploadFailureReason(err error) string {
+	switch err {
+	case imaging.ErrTooLarge, imaging.ErrTooManyPixels:
+		return "too_large"
+	case imaging.ErrUnsupportedType:
+		return "unsupported_type"
+	}
+	return "error"
+}
+
+func uploadError(err error) *apperr.Error {
+	switch err {
+	case imaging.ErrTooLarge:
+		return apperr.Validation("image_too_large", "Image too large, max 10MB")
+	case imaging.ErrTooManyPixels:
+		return apperr.Validation("image_too_large", "Image dimensions too large, max 40 megapixels")
+	case imaging.ErrUnsupportedType:
+		return apperr.Validation("unsupported_image", "Image must be jpeg, png or webp")
+	case errMediaNotFound:
+		return apperr.NotFound("media_not_found", "Banner media not found")
+	case errBannerNotProcessed:
+		return apperr.Validation("banner_not_processed", "Media is not a processed banner, upload the image as banner instead")
+	}
+	return apperr.Wrap(err, "Upload failed")
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"strconv"
+)
+
+type auditHandler struct {
+	service audit.AuditService
+}
+
+func NewAuditHandler(service audit.AuditService) *auditHandler {
+	return &auditHandler{service}
+}
+
+func (h *auditHandler) GetAuditLogs(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName != "super-admin" {
+		c.Error(apperr.Forbidden("You not have access for audit log"))
+		return
+	}
+
+	var filter audit.AuditFilterInput
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		c.Error(apperr.Input("Invalid audit filter", err))
+		return
+	}
+
+	page := c.Request.URL.Query().Get("page")
+	perPage := c.Request.URL.Query().Get("per_page")
+
+	paginate := helper.PaginateList(page, perPage)
+
+	logs, count, errLogs := h.service.GetListLog(c.Request.Context(), filter, paginate)
+	if errLogs != nil {
+		c.Error(apperr.Wrap(errLogs, "Error to get audit log"))
+		return
+	}
+
+	pageString, _ := strconv.Atoi(page)
+	pageSizeString, _ := strconv.Atoi(perPage)
+
+	response := helper.ApiResponseList("List Audit Log", http.StatusOK, "success", pageString, pageSizeString, count, audit.ListAuditJsonFormatter(logs))
+	c.JSON(http.StatusOK, response)
+}
+
+// recordAudit stores who changed what, a failing audit write is logged but never fails the request
+func recordAudit(service audit.AuditService, c *gin.Context, action string, entityType string, entityID uint, before interface{}, after interface{}) {
+	input := audit.AuditInput{
+		IPAddress:  c.ClientIP(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+	}
+
+	if value, exists := c.Get("currentUser"); exists {
+		if currentUser, ok := value.(model.User); ok {
+			input.ActorID = currentUser.ID
+			input.ActorName = currentUser.Name
+		}
+	}
+
+	err := service.Record(c.Request.Context(), input)
+	if err != nil {
+		log.Printf("audit error: %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/announcement"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
+	"nurul-iman-blok-m/broadcast"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/role"
+	"strings"
+)
+
+type broadcastHandler struct {
+	service             broadcast.BroadcastService
+	announcementService announcement.AnnouncementService
+	siteURL             string
+	auditService        audit.AuditService
+}
+
+// NewBroadcastHandler takes the public site short links redirect to
+func NewBroadcastHandler(service broadcast.BroadcastService, announcementService announcement.AnnouncementService, siteURL string, auditService audit.AuditService) *broadcastHandler {
+	return &broadcastHandler{service, announcementService, strings.TrimRight(siteURL, "/"), auditService}
+}
+
+func (h *broadcastHandler) ShareAnnouncement(c *gin.Context) {
+	announcementDetail, ok := h.shareableAnnouncement(c, isEditor(c))
+	if !ok {
+		return
+	}
+
+	text, err := h.service.ShareAnnouncement(c.Request.Context(), announcementDetail)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to compose message"))
+		return
+	}
+
+	response := helper.ApiResponse("Announcement message", http.StatusOK, "success", broadcast.ShareJsonFormatter(text))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *broadcastHandler) SendAnnouncement(c *gin.Context) {
+	input, ok := h.sendInput(c)
+	if !ok {
+		return
+	}
+
+	// only what visitors can already read is broadcast
+	announcementDetail, ok := h.shareableAnnouncement(c, false)
+	if !ok {
+		return
+	}
+
+	text, err := h.service.ShareAnnouncement(c.Request.Context(), announcementDetail)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to compose message"))
+		return
+	}
+
+	errSend := h.service.Send(c.Request.Context(), input, text)
+	if errSend != nil {
+		c.Error(apperr.Wrap(errSend, "Failed to send message"))
+		return
+	}
+
+	recordAudit(h.auditService, c, audit.ActionSend, audit.EntityAnnouncement, announcementDetail.ID, nil, gin.H{"target": input.Target, "text": text})
+
+	response := helper.ApiResponse("Announcement message sent", http.StatusOK, "success", broadcast.ShareJsonFormatter(text))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *broadcastHandler) ShareWeeklyRundown(c *gin.Context) {
+	text, ok := h.weeklyRundown(c)
+	if !ok {
+		return
+	}
+
+	response := helper.ApiResponse("Weekly rundown message", http.StatusOK, "success", broadcast.ShareJsonFormatter(text))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *broadcastHandler) SendWeeklyRundown(c *gin.Context) {
+	input, ok := h.sendInput(c)
+	if !ok {
+		return
+	}
+
+	text, ok := h.weeklyRundown(c)
+	if !ok {
+		return
+	}
+
+	errSend := h.service.Send(c.Request.Context(), input, text)
+	if errSend != nil {
+		c.Error(apperr.Wrap(errSend, "Failed to send message"))
+		return
+	}
+
+	recordAudit(h.auditService, c, audit.ActionSend, audit.EntityRundown, 0, nil, gin.H{"target": input.Target, "text": text})
+
+	response := helper.ApiResponse("Weekly rundown message sent", http.StatusOK, "success", broadcast.ShareJsonFormatter(text))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *broadcastHandler) GetTemplates(c *gin.Context) {
+	if !h.canBroadcast(c) {
+		return
+	}
+
+	templates, err := h.service.GetTemplates(c.Request.Context())
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Error to get message templates"))
+		return
+	}
+
+	response := helper.ApiResponse("List Message Template", http.StatusOK, "success", broadcast.ListTemplateJsonFormatter(templates))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *broadcastHandler) UpdateTemplate(c *gin.Context) {
+	nameInput, body, ok := h.templateInput(c)
+	if !ok {
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	before, _ := h.service.GetTemplates(c.Request.Context())
+
+	template, err := h.service.UpdateTemplate(c.Request.Context(), nameInput, body, currentUser.ID)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to update message template"))
+		return
+	}
+
+	recordAudit(h.auditService, c, audit.ActionUpdate, audit.EntityTemplate, 0, templateNamed(before, nameInput.Name), template)
+
+	response := helper.ApiResponse("Message template updated", http.StatusOK, "success", broadcast.TemplateJsonFormatter(template))
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetTemplate drops the edited text, the built-in template applies again
+func (h *broadcastHandler) ResetTemplate(c *gin.Context) {
+	var nameInput broadcast.TemplateNameInput
+	err := c.ShouldBindUri(&nameInput)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Message template not found"))
+		return
+	}
+
+	if !h.canBroadcast(c) {
+		return
+	}
+
+	before, _ := h.service.GetTemplates(c.Request.Context())
+
+	template, errReset := h.service.ResetTemplate(c.Request.Context(), nameInput)
+	if errReset != nil {
+		c.Error(apperr.Wrap(errReset, "Failed to reset message template"))
+		return
+	}
+
+	recordAudit(h.auditService, c, audit.ActionDelete, audit.EntityTemplate, 0, templateNamed(before, nameInput.Name), template)
+
+	response := helper.ApiResponse("Message template reset", http.StatusOK, "success", broadcast.TemplateJsonFormatter(template))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *broadcastHandler) PreviewTemplate(c *gin.Context) {
+	nameInput, body, ok := h.templateInput(c)
+	if !ok {
+		return
+	}
+
+	text, err := h.service.Preview(c.Request.Context(), nameInput, body)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Failed to render message template"))
+		return
+	}
+
+	response := helper.ApiResponse("Message template preview", http.StatusOK, "success", broadcast.ShareJsonFormatter(text))
+	c.JSON(http.StatusOK, response)
+}
+
+// ShortLink redirects the short links inside shared messages to the public site
+func (h *broadcastHandler) ShortLink(c *gin.Context) {
+	var input broadcast.ShortLinkInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Redirect(http.StatusFound, h.siteURL)
+		return
+	}
+
+	kind, ID, ok := broadcast.ParseShortCode(input.Code)
+	switch {
+	case ok && kind == broadcast.LinkAnnouncement && ID != 0:
+		announcementDetail, errDetail := h.announcementService.GetDetailAnnouncement(c.Request.Context(), announcement.AnnouncementDetailInput{ID: ID})
+		if errDetail == nil && announcement.IsPublic(announcementDetail) {
+			c.Redirect(http.StatusFound, h.siteURL+"/announcements/"+announcementDetail.Slug)
+			return
+		}
+	case ok && kind == broadcast.LinkWeeklyRundown:
+		c.Redirect(http.StatusFound, h.siteURL+"/rundown")
+		return
+	}
+
+	c.Redirect(http.StatusFound, h.siteURL)
+}
+
+func (h *broadcastHandler) shareableAnnouncement(c *gin.Context, editor bool) (model.Announcement, bool) {
+	var input announcement.AnnouncementDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Announcement detail not found"))
+		return model.Announcement{}, false
+	}
+
+	announcementDetail, errDetail := h.announcementService.GetDetailAnnouncement(c.Request.Context(), input)
+	if errDetail != nil {
+		c.Error(apperr.Wrap(errDetail, "Announcement detail not found"))
+		return model.Announcement{}, false
+	}
+	if !editor && !announcement.IsPublic(announcementDetail) {
+		c.Error(apperr.NotFound("announcement_not_found", "Announcement detail not found"))
+		return model.Announcement{}, false
+	}
+
+	return announcementDetail, true
+}
+
+func (h *broadcastHandler) weeklyRundown(c *gin.Context) (string, bool) {
+	var input broadcast.WeeklyShareInput
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		c.Error(apperr.Input("Invalid week", err))
+		return "", false
+	}
+
+	text, errShare := h.service.ShareWeeklyRundown(c.Request.Context(), input)
+	if errShare != nil {
+		c.Error(apperr.Wrap(errShare, "Failed to compose message"))
+		return "", false
+	}
+
+	return text, true
+}
+
+func (h *broadcastHandler) sendInput(c *gin.Context) (broadcast.SendInput, bool) {
+	var input broadcast.SendInput
+	if c.Request.ContentLength != 0 {
+		err := c.ShouldBindJSON(&input)
+		if err != nil {
+			c.Error(apperr.Input("You must completed field", err))
+			return input, false
+		}
+	}
+
+	return input, h.canBroadcast(c)
+}
+
+func (h *broadcastHandler) templateInput(c *gin.Context) (broadcast.TemplateNameInput, broadcast.TemplateInput, bool) {
+	var nameInput broadcast.TemplateNameInput
+	var body broadcast.TemplateInput
+
+	err := c.ShouldBindUri(&nameInput)
+	if err != nil {
+		c.Error(apperr.NotFound("template_not_found", "Message template not found"))
+		return nameInput, body, false
+	}
+
+	errBody := c.ShouldBindJSON(&body)
+	if errBody != nil {
+		c.Error(apperr.Input("You must completed field", errBody))
+		return nameInput, body, false
+	}
+
+	return nameInput, body, h.canBroadcast(c)
+}
+
+func (h *broadcastHandler) canBroadcast(c *gin.Context) bool {
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionBroadcast) {
+		c.Error(apperr.Forbidden("You not have access for broadcast"))
+		return false
+	}
+	return true
+}
+
+func templateNamed(templates []broadcast.Template, name string) interface{} {
+	for _, template := range templates {
+		if template.Name == name {
+			return template
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"mime"
+	"net/http"
+	"nurul-iman-blok-m/announcement"
+	"nurul-iman-blok-m/feed"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/media"
+	"nurul-iman-blok-m/model"
+	"path"
+	"strings"
+	"time"
+)
+
+// feedSize is the number of announcements in every feed
+const feedSize = "50"
+
+type feedHandler struct {
+	announcementService announcement.AnnouncementService
+	mediaService        media.MediaService
+	siteURL             string
+	feedURL             string
+}
+
+// NewFeedHandler takes the public site used for item links and the base url the feeds are served from
+func NewFeedHandler(announcementService announcement.AnnouncementService, mediaService media.MediaService, siteURL string, feedURL string) *feedHandler {
+	return &feedHandler{announcementService, mediaService, siteURL, feedURL}
+}
+
+func (h *feedHandler) AnnouncementsRSS(c *gin.Context) {
+	h.serveAnnouncements(c, "announcements.rss", feed.RSSContentType, feed.RSS)
+}
+
+func (h *feedHandler) AnnouncementsAtom(c *gin.Context) {
+	h.serveAnnouncements(c, "announcements.atom", feed.AtomContentType, feed.Atom)
+}
+
+func (h *feedHandler) AnnouncementsJSON(c *gin.Context) {
+	h.serveAnnouncements(c, "announcements.json", feed.JSONContentType, feed.JSON)
+}
+
+func (h *feedHandler) serveAnnouncements(c *gin.Context, name string, contentType string, render func(feed.Channel) ([]byte, error)) {
+	// newest first, scheduled announcements count from the moment they went live
+	list := func(db *gorm.DB) *gorm.DB {
+		return helper.PaginateList("1", feedSize)(db).Order("COALESCE(publish_at, created_at) desc")
+	}
+
+	announcements, _, err := h.announcementService.GetListAnnouncement(c.Request.Context(), announcement.AnnouncementListInput{PublishedOnly: true}, list)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "failed to load feed")
+		return
+	}
+
+	channel := feed.Channel{
+		Title:       "Pengumuman Masjid Nurul Iman Blok M",
+		Link:        h.siteURL,
+		FeedURL:     h.feedURL + "/feeds/" + name,
+		Description: "Pengumuman terbaru Masjid Nurul Iman Blok M",
+		Language:    "id",
+		Author:      "Masjid Nurul Iman Blok M",
+		Items:       h.announcementItems(c.Request.Context(), announcements),
+	}
+
+	body, err := render(channel)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "failed to render feed")
+		return
+	}
+
+	lastModified := feed.LastModified(channel).UTC().Truncate(time.Second)
+	etag := feed.ETag(body)
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func (h *feedHandler) announcementItems(ctx context.Context, announcements []model.Announcement) []feed.Item {
+	// banner sizes come from the media library, older banners are not in it and go out without a length
+	var banners []string
+	for _, item := range announcements {
+		banners = append(banners, item.Images)
+	}
+	sizes := map[string]int64{}
+	assets, _ := h.mediaService.GetAssetsByURL(ctx, banners...)
+	for _, asset := range assets {
+		sizes[asset.URL] = asset.Size
+	}
+
+	items := []feed.Item{}
+	for _, item := range announcements {
+		published := item.CreatedAt
+		if item.PublishAt != nil {
+			published = *item.PublishAt
+		}
+
+		// the slug changes with the title, readers would show an edited item as a new one
+		feedItem := feed.Item{
+			ID:        fmt.Sprintf("urn:nurul-iman:announcement:%d", item.ID),
+			Title:     item.Title,
+			Link:      h.siteURL + "/announcements/" + item.Slug,
+			Summary:   item.Description,
+			Author:    item.User.Name,
+			Published: published,
+			Updated:   item.UpdatedAt,
+		}
+		if item.Images != "" {
+			feedItem.Enclosure = &feed.Enclosure{
+				URL:    item.Images,
+				Type:   mime.TypeByExtension(path.Ext(item.Images)),
+				Length: sizes[item.Images],
+			}
+		}
+		items = append(items, feedItem)
+	}
+	return items
+}
+
+// notModified follows RFC 7232, If-None-Match wins over If-Modified-Since when both are sent
+func notModified(request *http.Request, etag string, lastModified time.Time) bool {
+	if match := request.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := request.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		sinceTime, err := http.ParseTime(since)
+		if err == nil && !lastModified.After(sinceTime) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	etag := `"5d41402abc4b2a76b9719d911017c592"`
+	lastModified := time.Date(2026, 10, 16, 5, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		lastModified time.Time
+		want         bool
+	}{
+		{name: "no validators", lastModified: lastModified, want: false},
+		{name: "matching etag", headers: map[string]string{"If-None-Match": etag}, lastModified: lastModified, want: true},
+		{name: "weak matching etag", headers: map[string]string{"If-None-Match": "W/" + etag}, lastModified: lastModified, want: true},
+		{name: "etag in a list", headers: map[string]string{"If-None-Match": `"other", ` + etag}, lastModified: lastModified, want: true},
+		{name: "wildcard", headers: map[string]string{"If-None-Match": "*"}, lastModified: lastModified, want: true},
+		{name: "stale etag", headers: map[string]string{"If-None-Match": `"other"`}, lastModified: lastModified, want: false},
+		{
+			name:         "etag wins over a fresh date",
+			headers:      map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat)},
+			lastModified: lastModified,
+			want:         false,
+		},
+		{name: "same date", headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, lastModified: lastModified, want: true},
+		{name: "later date", headers: map[string]string{"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat)}, lastModified: lastModified, want: true},
+		{name: "earlier date", headers: map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, lastModified: lastModified, want: false},
+		{name: "unparsable date", headers: map[string]string{"If-Modified-Since": "yesterday"}, lastModified: lastModified, want: false},
+		{name: "unknown last modified", headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/feeds/announcements.xml", nil)
+			for key, value := range test.headers {
+				request.Header.Set(key, value)
+			}
+			if got := notModified(request, etag, test.lastModified); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"nurul-iman-blok-m/helper"
+	"sync"
+	"time"
+)
+
+// ReadinessCheck reports whether a dependency the api needs is reachable
+type ReadinessCheck func(ctx context.Context) error
+
+type healthHandler struct {
+	checks  map[string]ReadinessCheck
+	timeout time.Duration
+}
+
+func NewHealthHandler(checks map[string]ReadinessCheck) *healthHandler {
+	return &healthHandler{checks, 3 * time.Second}
+}
+
+// Liveness only says the process serves requests, it never touches dependencies,
+// so a database outage does not get every dyno restarted
+func (h *healthHandler) Liveness(c *gin.Context) {
+	response := helper.ApiResponse("OK", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// Readiness runs every check at once, the api is ready when all of them pass
+func (h *healthHandler) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	results := map[string]string{}
+	ready := true
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func(name string, check ReadinessCheck) {
+			defer wg.Done()
+
+			err := check(ctx)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				log.Printf("readiness check %s failed: %v", name, err)
+				results[name] = "unavailable"
+				ready = false
+				return
+			}
+			results[name] = "ok"
+		}(name, check)
+	}
+	wg.Wait()
+
+	if !ready {
+		response := helper.ApiResponse("Not ready", http.StatusServiceUnavailable, "error", results)
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	response := helper.ApiResponse("Ready", http.StatusOK, "success", results)
+	c.JSON(http.StatusOK, response)
+}
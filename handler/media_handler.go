@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/media"
+	"nurul-iman-blok-m/model"
+	"strconv"
+)
+
+type mediaHandler struct {
+	service      media.MediaService
+	auditService audit.AuditService
+}
+
+func NewMediaHandler(service media.MediaService, auditService audit.AuditService) *mediaHandler {
+	return &mediaHandler{service, auditService}
+}
+
+func (h *mediaHandler) GetListMedia(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName == "user" {
+		c.Error(apperr.Forbidden("You not have access for media library"))
+		return
+	}
+
+	var filter media.MediaListInput
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		c.Error(apperr.Input("Invalid media filter", err))
+		return
+	}
+
+	page := c.Request.URL.Query().Get("page")
+	perPage := c.Request.URL.Query().Get("per_page")
+
+	paginate := helper.PaginateList(page, perPage)
+
+	assets, count, errAssets := h.service.GetListAsset(c.Request.Context(), filter, paginate)
+	if errAssets != nil {
+		c.Error(apperr.Wrap(errAssets, "Error to get media"))
+		return
+	}
+
+	pageString, _ := strconv.Atoi(page)
+	pageSizeString, _ := strconv.Atoi(perPage)
+
+	response := helper.ApiResponseList("List Media", http.StatusOK, "success", pageString, pageSizeString, count, media.ListMediaJsonFormatter(assets))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *mediaHandler) GetDetailMedia(c *gin.Context) {
+	var input media.MediaDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Media not found"))
+		return
+	}
+
+	asset, errAsset := h.service.GetAsset(c.Request.Context(), input)
+	if errAsset != nil {
+		c.Error(apperr.Wrap(errAsset, "Media not found"))
+		return
+	}
+
+	response := helper.ApiResponse("Detail Media", http.StatusOK, "success", media.MediaJsonFormatter(asset))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *mediaHandler) AttachMedia(c *gin.Context) {
+	var input media.MediaDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Media not found"))
+		return
+	}
+
+	var attach media.AttachInput
+	err = c.ShouldBindJSON(&attach)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName == "user" {
+		c.Error(apperr.Forbidden("You not have access for media library"))
+		return
+	}
+
+	errAttach := h.service.Attach(c.Request.Context(), input, attach)
+	if errAttach != nil {
+		c.Error(apperr.Wrap(errAttach, "Failed to attach media"))
+		return
+	}
+
+	recordAudit(h.auditService, c, audit.ActionUpdate, attach.EntityType, attach.EntityID, nil, gin.H{"attached_media_id": input.ID})
+
+	response := helper.ApiResponse("Media attached", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *mediaHandler) GetAttachments(c *gin.Context) {
+	var input media.AttachmentListInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Attachments not found"))
+		return
+	}
+
+	attachments, errAttachments := h.service.GetAttachments(c.Request.Context(), input)
+	if errAttachments != nil {
+		c.Error(apperr.Wrap(errAttachments, "Error to get attachments"))
+		return
+	}
+
+	response := helper.ApiResponse("List Attachment", http.StatusOK, "success", media.ListMediaJsonFormatter(attachments))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/push"
+)
+
+type pushHandler struct {
+	service push.PushService
+}
+
+func NewPushHandler(service push.PushService) *pushHandler {
+	return &pushHandler{service}
+}
+
+// RegisterDevice works for anonymous installations too, a token sent with a login is linked to the user
+func (h *pushHandler) RegisterDevice(c *gin.Context) {
+	var input push.DeviceInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	var userID *uint
+	if value, exists := c.Get("currentUser"); exists {
+		if currentUser, ok := value.(model.User); ok {
+			userID = &currentUser.ID
+		}
+	}
+
+	device, errRegister := h.service.RegisterDevice(c.Request.Context(), input, userID)
+	if errRegister != nil {
+		c.Error(apperr.Wrap(errRegister, "Failed to register device"))
+		return
+	}
+
+	response := helper.ApiResponse("Device registered", http.StatusOK, "success", push.DeviceJsonFormatter(device))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *pushHandler) UnregisterDevice(c *gin.Context) {
+	var input push.TokenInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	errUnregister := h.service.UnregisterDevice(c.Request.Context(), input)
+	if errUnregister != nil {
+		c.Error(apperr.Wrap(errUnregister, "Failed to unregister device"))
+		return
+	}
+
+	response := helper.ApiResponse("Device unregistered", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *pushHandler) SetTopics(c *gin.Context) {
+	var input push.TopicInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	device, errTopics := h.service.SetTopics(c.Request.Context(), input)
+	if errTopics != nil {
+		c.Error(apperr.Wrap(errTopics, "Failed to update topics"))
+		return
+	}
+
+	response := helper.ApiResponse("Topics updated", http.StatusOK, "success", push.DeviceJsonFormatter(device))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/recording"
+	"strconv"
+)
+
+type recordingHandler struct {
+	service      recording.RecordingService
+	podcast      recording.PodcastInfo
+	auditService audit.AuditService
+}
+
+func NewRecordingHandler(service recording.RecordingService, podcast recording.PodcastInfo, auditService audit.AuditService) *recordingHandler {
+	return &recordingHandler{service, podcast, auditService}
+}
+
+func (h *recordingHandler) AddRecording(c *gin.Context) {
+	var input recording.RecordingInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName == "user" || currentUser.Role.RoleName == "admin" {
+		c.Error(apperr.Forbidden("You not have access for add"))
+		return
+	}
+
+	added, errAdd := h.service.AddRecording(c.Request.Context(), input, currentUser.ID)
+	if errAdd != nil {
+		c.Error(apperr.Wrap(errAdd, "Failed to add recording"))
+		return
+	}
+
+	recordAudit(h.auditService, c, audit.ActionCreate, audit.EntityRecording, added.ID, nil, added)
+
+	response := helper.ApiResponse("Success to add recording", http.StatusOK, "success", recording.RecordingJsonFormatter(added))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *recordingHandler) GetAllRecording(c *gin.Context) {
+	var input recording.RecordingListInput
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		c.Error(apperr.Input("Invalid filter", err))
+		return
+	}
+
+	page := c.Request.URL.Query().Get("page")
+	perPage := c.Request.URL.Query().Get("per_page")
+
+	paginate := helper.PaginateList(page, perPage)
+
+	recordings, count, errList := h.service.GetListRecording(c.Request.Context(), input, paginate)
+	if errList != nil {
+		c.Error(apperr.Wrap(errList, "Error to get recordings"))
+		return
+	}
+
+	pageString, _ := strconv.Atoi(page)
+	pageSizeString, _ := strconv.Atoi(perPage)
+
+	response := helper.ApiResponseList("List Recording", http.StatusOK, "success", pageString, pageSizeString, count, recording.ListRecordingJsonFormatter(recordings))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *recordingHandler) GetDetailRecording(c *gin.Context) {
+	var input recording.RecordingDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Recording not found"))
+		return
+	}
+
+	detail, errDetail := h.service.GetDetailRecording(c.Request.Context(), input)
+	if errDetail != nil {
+		c.Error(apperr.Wrap(errDetail, "Recording not found"))
+		return
+	}
+
+	response := helper.ApiResponse("Recording Detail", http.StatusOK, "success", recording.RecordingJsonFormatter(detail))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *recordingHandler) DeleteRecording(c *gin.Context) {
+	var input recording.RecordingDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.Input("Delete Failed", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName == "user" || currentUser.Role.RoleName == "admin" {
+		c.Error(apperr.Forbidden("You not have access for delete"))
+		return
+	}
+
+	before, _ := h.service.GetDetailRecording(c.Request.Context(), input)
+	errDelete := h.service.DeleteRecording(c.Request.Context(), input)
+	if errDelete != nil {
+		c.Error(apperr.Wrap(errDelete, "Delete failed"))
+		return
+	}
+	recordAudit(h.auditService, c, audit.ActionDelete, audit.EntityRecording, input.ID, before, nil)
+
+	response := helper.ApiResponse("Delete Success", http.StatusOK, "Success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// Podcast serves the recordings as an itunes compatible feed, podcast apps subscribe to it directly
+func (h *recordingHandler) Podcast(c *gin.Context) {
+	recordings, err := h.service.GetFeedRecordings(c.Request.Context())
+	if err != nil {
+		c.String(http.StatusInternalServerError, "failed to load podcast")
+		return
+	}
+
+	feed, err := recording.PodcastFeed(h.podcast, recordings)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "failed to render podcast")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", feed)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/review"
+	"nurul-iman-blok-m/role"
+	"strconv"
+)
+
+type reviewHandler struct {
+	service      review.ReviewService
+	auditService audit.AuditService
+}
+
+func NewReviewHandler(service review.ReviewService, auditService audit.AuditService) *reviewHandler {
+	return &reviewHandler{service, auditService}
+}
+
+func (h *reviewHandler) SubmitReview(c *gin.Context) {
+	var input review.ReviewSubmitInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionSubmitReview) {
+		c.Error(apperr.Forbidden("You not have access for submit review"))
+		return
+	}
+	input.Submitter = review.Submitter{UserID: currentUser.ID, CanPublish: role.Can(currentUser.Role.RoleName, role.PermissionPublish)}
+
+	submitted, errSubmit := h.service.Submit(c.Request.Context(), input)
+	if errSubmit != nil {
+		c.Error(apperr.Wrap(errSubmit, "Failed to submit review"))
+		return
+	}
+	recordAudit(h.auditService, c, audit.ActionSubmit, input.ContentType, input.ContentID, nil, submitted)
+
+	response := helper.ApiResponse("Success to submit review", http.StatusOK, "success", review.ReviewJsonFormatter(submitted))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *reviewHandler) ApproveReview(c *gin.Context) {
+	var input review.ReviewDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Review not found"))
+		return
+	}
+
+	var decision review.ReviewDecisionInput
+	_ = c.ShouldBindJSON(&decision)
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionApproveReview) {
+		c.Error(apperr.Forbidden("You not have access for approve"))
+		return
+	}
+
+	approved, errApprove := h.service.Approve(c.Request.Context(), input, currentUser.ID, decision.Comment)
+	if errApprove != nil {
+		c.Error(apperr.Wrap(errApprove, "Failed to approve"))
+		return
+	}
+	recordAudit(h.auditService, c, audit.ActionApprove, approved.ContentType, approved.ContentID, nil, approved)
+
+	response := helper.ApiResponse("Success to approve", http.StatusOK, "success", review.ReviewJsonFormatter(approved))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *reviewHandler) RejectReview(c *gin.Context) {
+	var input review.ReviewDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Review not found"))
+		return
+	}
+
+	var decision review.ReviewRejectInput
+	errDecision := c.ShouldBindJSON(&decision)
+	if errDecision != nil {
+		c.Error(apperr.Input("You must completed field", errDecision))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionApproveReview) {
+		c.Error(apperr.Forbidden("You not have access for reject"))
+		return
+	}
+
+	rejected, errReject := h.service.Reject(c.Request.Context(), input, currentUser.ID, decision.Comment)
+	if errReject != nil {
+		c.Error(apperr.Wrap(errReject, "Failed to reject"))
+		return
+	}
+	recordAudit(h.auditService, c, audit.ActionReject, rejected.ContentType, rejected.ContentID, nil, rejected)
+
+	response := helper.ApiResponse("Success to reject", http.StatusOK, "success", review.ReviewJsonFormatter(rejected))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *reviewHandler) GetQueue(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionApproveReview) {
+		c.Error(apperr.Forbidden("You not have access for review queue"))
+		return
+	}
+
+	page := c.Request.URL.Query().Get("page")
+	perPage := c.Request.URL.Query().Get("per_page")
+
+	paginate := helper.PaginateList(page, perPage)
+
+	reviews, count, err := h.service.GetQueue(c.Request.Context(), paginate)
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Error to get review queue"))
+		return
+	}
+
+	pageString, _ := strconv.Atoi(page)
+	pageSizeString, _ := strconv.Atoi(perPage)
+
+	response := helper.ApiResponseList("Review Queue", http.StatusOK, "success", pageString, pageSizeString, count, review.ListReviewJsonFormatter(reviews))
+	c.JSON(http.StatusOK, response)
+}
@@ -3,16 +3,19 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
 	"nurul-iman-blok-m/helper"
 	"nurul-iman-blok-m/role"
 )
 
 type roleHandler struct {
-	roleService role.RoleService
+	roleService  role.RoleService
+	auditService audit.AuditService
 }
 
-func NewRoleHandler(service role.RoleService) *roleHandler {
-	return &roleHandler{service}
+func NewRoleHandler(service role.RoleService, auditService audit.AuditService) *roleHandler {
+	return &roleHandler{service, auditService}
 }
 
 func (h *roleHandler) SaveRole(c *gin.Context) {
@@ -20,20 +23,18 @@ func (h *roleHandler) SaveRole(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		errors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": errors}
-		response := helper.ApiResponse("Add new role failed", http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperr.Input("Add new role failed", err))
 		return
 	}
 
-	roleInput, errAddRole := h.roleService.SaveRole(input)
+	roleInput, errAddRole := h.roleService.SaveRole(c.Request.Context(), input)
 	if errAddRole != nil {
-		response := helper.ApiResponse("Add new role failed", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errAddRole, "Add new role failed"))
 		return
 	}
 
+	recordAudit(h.auditService, c, audit.ActionCreate, audit.EntityRole, roleInput.ID, nil, roleInput)
+
 	formatter := role.RoleJsonFormatter(roleInput)
 
 	response := helper.ApiResponse("Success", http.StatusOK, "success", formatter)
@@ -44,10 +45,9 @@ func (h *roleHandler) SaveRole(c *gin.Context) {
 func (h *roleHandler) GetRoles(c *gin.Context) {
 	roleName := c.Query("role_name")
 
-	roles, err := h.roleService.GetRoles(roleName)
+	roles, err := h.roleService.GetRoles(c.Request.Context(), roleName)
 	if err != nil {
-		response := helper.ApiResponse("Error to get roles", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(err, "Error to get roles"))
 		return
 	}
 
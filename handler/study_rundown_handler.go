@@ -3,6 +3,8 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
 	"nurul-iman-blok-m/helper"
 	"nurul-iman-blok-m/model"
 	"nurul-iman-blok-m/study_rundown"
@@ -10,11 +12,12 @@ import (
 )
 
 type StudyRundownHandler struct {
-	service study_rundown.StudyService
+	service      study_rundown.StudyService
+	auditService audit.AuditService
 }
 
-func NewHandlerStudyRundown(service study_rundown.StudyService) *StudyRundownHandler {
-	return &StudyRundownHandler{service}
+func NewHandlerStudyRundown(service study_rundown.StudyService, auditService audit.AuditService) *StudyRundownHandler {
+	return &StudyRundownHandler{service, auditService}
 }
 
 func (h *StudyRundownHandler) AddStudy(c *gin.Context) {
@@ -22,28 +25,24 @@ func (h *StudyRundownHandler) AddStudy(c *gin.Context) {
 
 	err := c.ShouldBind(&input)
 	if err != nil {
-		errors := helper.FormatValidationError(err)
-		errMessage := gin.H{"errors": errors}
-
-		response := helper.ApiResponse("you must complete field", http.StatusBadRequest, "error", errMessage)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Input("you must complete field", err))
 		return
 	}
 	currentUser := c.MustGet("currentUser").(model.User)
 
 	if currentUser.Role.RoleName == "admin" {
-		response := helper.ApiResponse("You not have access for add", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Forbidden("You not have access for add"))
 		return
 	}
 
-	study, errAdd := h.service.AddStudy(input)
+	study, errAdd := h.service.AddStudy(c.Request.Context(), input)
 	if errAdd != nil {
-		response := helper.ApiResponse("Failed to add rundown", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errAdd, "Failed to add rundown"))
 		return
 	}
 
+	recordAudit(h.auditService, c, audit.ActionCreate, audit.EntityRundown, study.ID, nil, study)
+
 	formatter := study_rundown.StudyResponseFormat(study)
 
 	response := helper.ApiResponse("Success to add rundown", http.StatusOK, "success", formatter)
@@ -52,10 +51,9 @@ func (h *StudyRundownHandler) AddStudy(c *gin.Context) {
 }
 
 func (h *StudyRundownHandler) GetListUstadzName(c *gin.Context) {
-	name, err := h.service.GetListUstadName()
+	name, err := h.service.GetListUstadName(c.Request.Context())
 	if err != nil {
-		response := helper.ApiResponse("Error to get ustadz name", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(err, "Error to get ustadz name"))
 		return
 	}
 
@@ -69,10 +67,9 @@ func (h *StudyRundownHandler) GetAllRundown(c *gin.Context) {
 
 	paginate := helper.PaginateList(page, perPage)
 
-	listStudy, count, err := h.service.GetListStudy(paginate)
+	listStudy, count, err := h.service.GetListStudy(c.Request.Context(), paginate)
 	if err != nil {
-		response := helper.ApiResponse("Error to get rundown", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(err, "Error to get rundown"))
 		return
 	}
 
@@ -87,15 +84,13 @@ func (h *StudyRundownHandler) GetDetailStudyRundown(c *gin.Context) {
 	var input study_rundown.StudyRundownInputDetail
 	err := c.ShouldBindUri(&input)
 	if err != nil {
-		response := helper.ApiResponse("Rundown detail not found", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Rundown detail not found"))
 		return
 	}
 
-	studyRundown, errDetail := h.service.DetailStudy(input)
+	studyRundown, errDetail := h.service.DetailStudy(c.Request.Context(), input)
 	if errDetail != nil {
-		response := helper.ApiResponse("Failed to get detail Rundown", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errDetail, "Failed to get detail Rundown"))
 		return
 	}
 
@@ -107,22 +102,21 @@ func (h *StudyRundownHandler) DeleteStudyRundown(c *gin.Context) {
 	var input study_rundown.StudyRundownInputDetail
 	err := c.ShouldBindUri(&input)
 	if err != nil {
-		response := helper.ApiResponse("Delete Failed", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Input("Delete Failed", err))
 		return
 	}
 	currentUser := c.MustGet("currentUser").(model.User)
 	if currentUser.Role.RoleName == "admin" {
-		response := helper.ApiResponse("You not have access for delete", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Forbidden("You not have access for delete"))
 		return
 	}
-	errDelete := h.service.DeleteStudy(input)
+	before, _ := h.service.DetailStudy(c.Request.Context(), input)
+	errDelete := h.service.DeleteStudy(c.Request.Context(), input)
 	if errDelete != nil {
-		response := helper.ApiResponse("Delete failed", http.StatusBadRequest, "error", errDelete)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errDelete, "Delete failed"))
 		return
 	}
+	recordAudit(h.auditService, c, audit.ActionDelete, audit.EntityRundown, input.ID, before, nil)
 	response := helper.ApiResponse("Delete Success", http.StatusOK, "Success", nil)
 	c.JSON(http.StatusOK, response)
 }
@@ -131,8 +125,7 @@ func (h *StudyRundownHandler) UpdateStudyRundown(c *gin.Context) {
 	var inputID study_rundown.StudyRundownInputDetail
 	err := c.ShouldBindUri(&inputID)
 	if err != nil {
-		response := helper.ApiResponse("Failed To Update because ID not found", http.StatusBadRequest, "Error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Failed To Update because ID not found"))
 		return
 	}
 
@@ -140,26 +133,22 @@ func (h *StudyRundownHandler) UpdateStudyRundown(c *gin.Context) {
 	errInputUpdate := c.ShouldBind(&inputUpdate)
 
 	if errInputUpdate != nil {
-		errors := helper.FormatValidationError(err)
-		errMessage := gin.H{"errors": errors}
-
-		response := helper.ApiResponse("You must completed field", http.StatusUnprocessableEntity, "error", errMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperr.Input("You must completed field", errInputUpdate))
 		return
 	}
 	currentUser := c.MustGet("currentUser").(model.User)
 
 	if currentUser.Role.RoleName == "user" {
-		response := helper.ApiResponse("You not have access for update", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Forbidden("You not have access for update"))
 		return
 	}
-	updateData, errUpdateData := h.service.UpdateStudy(inputUpdate, inputID)
+	before, _ := h.service.DetailStudy(c.Request.Context(), inputID)
+	updateData, errUpdateData := h.service.UpdateStudy(c.Request.Context(), inputUpdate, inputID)
 	if errUpdateData != nil {
-		response := helper.ApiResponse("Failed to update announcement", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errUpdateData, "Failed to update announcement"))
 		return
 	}
+	recordAudit(h.auditService, c, audit.ActionUpdate, audit.EntityRundown, updateData.ID, before, updateData)
 
 	formatter := study_rundown.StudyResponseFormat(updateData)
 
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/trash"
+)
+
+type trashHandler struct {
+	service      trash.TrashService
+	auditService audit.AuditService
+}
+
+func NewTrashHandler(service trash.TrashService, auditService audit.AuditService) *trashHandler {
+	return &trashHandler{service, auditService}
+}
+
+func (h *trashHandler) GetTrash(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName != "super-admin" {
+		c.Error(apperr.Forbidden("You not have access for trash"))
+		return
+	}
+
+	items, err := h.service.GetTrash(c.Request.Context(), c.Query("resource"))
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Error to get trash"))
+		return
+	}
+
+	response := helper.ApiResponse("List of trash", http.StatusOK, "success", trash.TrashListJsonFormatter(items, h.service.RetentionPeriod()))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *trashHandler) RestoreAnnouncement(c *gin.Context) {
+	h.restore(c, trash.ResourceAnnouncement, audit.EntityAnnouncement)
+}
+
+func (h *trashHandler) RestoreStudyRundown(c *gin.Context) {
+	h.restore(c, trash.ResourceRundown, audit.EntityRundown)
+}
+
+func (h *trashHandler) RestoreArticle(c *gin.Context) {
+	h.restore(c, trash.ResourceArticle, audit.EntityArticle)
+}
+
+func (h *trashHandler) restore(c *gin.Context, resource string, entityType string) {
+	var input trash.TrashRestoreInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.Input("Restore failed", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName != "super-admin" {
+		c.Error(apperr.Forbidden("You not have access for restore"))
+		return
+	}
+
+	errRestore := h.service.Restore(c.Request.Context(), resource, input)
+	if errRestore != nil {
+		c.Error(apperr.Wrap(errRestore, "Restore failed"))
+		return
+	}
+
+	recordAudit(h.auditService, c, audit.ActionRestore, entityType, input.ID, nil, nil)
+
+	response := helper.ApiResponse("Restore Success", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/storage"
+	"nurul-iman-blok-m/upload"
+)
+
+type uploadHandler struct {
+	service upload.UploadService
+	local   *storage.LocalStorage
+}
+
+// NewUploadHandler takes the local storage only in development, nil when files go to S3
+func NewUploadHandler(service upload.UploadService, local *storage.LocalStorage) *uploadHandler {
+	return &uploadHandler{service, local}
+}
+
+func (h *uploadHandler) Presign(c *gin.Context) {
+	var input upload.PresignInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if currentUser.Role.RoleName == "user" {
+		c.Error(apperr.Forbidden("You not have access for upload"))
+		return
+	}
+
+	pending, request, errPresign := h.service.Presign(c.Request.Context(), input, currentUser.ID)
+	if errPresign != nil {
+		c.Error(apperr.Wrap(errPresign, "Failed to create upload url"))
+		return
+	}
+
+	response := helper.ApiResponse("Upload url created", http.StatusOK, "success", upload.PresignJsonFormatter(pending, request))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *uploadHandler) Complete(c *gin.Context) {
+	var input upload.CompleteInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+
+	completed, errComplete := h.service.Complete(c.Request.Context(), input, currentUser.ID)
+	if errComplete != nil {
+		c.Error(apperr.Wrap(errComplete, "Failed to complete upload"))
+		return
+	}
+
+	response := helper.ApiResponse("Upload completed", http.StatusOK, "success", upload.UploadJsonFormatter(completed))
+	c.JSON(http.StatusOK, response)
+}
+
+// PutLocal plays the part of the bucket for presigned urls handed out by the local storage
+func (h *uploadHandler) PutLocal(c *gin.Context) {
+	if h.local == nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Local storage is disabled"))
+		return
+	}
+
+	var input upload.LocalUploadInput
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		c.Error(apperr.Forbidden("Invalid upload url"))
+		return
+	}
+
+	errReceive := h.local.Receive(input.Key, c.ContentType(), input.Expires, input.Signature, c.Request.Body, upload.MaxSize)
+	if errReceive != nil {
+		c.Error(apperr.Wrap(errReceive, "Upload failed"))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -3,20 +3,24 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
 	"nurul-iman-blok-m/auth"
 	"nurul-iman-blok-m/helper"
 	"nurul-iman-blok-m/user"
 )
 
 type userHandler struct {
-	userService user.UserService
-	authService auth.Service
+	userService  user.UserService
+	authService  auth.Service
+	auditService audit.AuditService
 }
 
-func NewUserHandler(userService user.UserService, authService auth.Service) *userHandler {
+func NewUserHandler(userService user.UserService, authService auth.Service, auditService audit.AuditService) *userHandler {
 	return &userHandler{
-		userService: userService,
-		authService: authService,
+		userService:  userService,
+		authService:  authService,
+		auditService: auditService,
 	}
 }
 
@@ -29,26 +33,23 @@ func (h *userHandler) RegisterUser(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		errors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": errors}
-		response := helper.ApiResponse("Register account failed", http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperr.Input("Register account failed", err))
 		return
 	}
 
 	// next for add user must be super admin
 
-	userInput, roleName, errInput := h.userService.RegisterUser(input)
+	userInput, roleName, errInput := h.userService.RegisterUser(c.Request.Context(), input)
 	if errInput != nil {
-		response := helper.ApiResponse("Register account failed", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errInput, "Register account failed"))
 		return
 	}
 
+	recordAudit(h.auditService, c, audit.ActionCreate, audit.EntityUser, userInput.ID, nil, userInput)
+
 	token, errToken := h.authService.GenerateToken(userInput.ID)
 	if errToken != nil {
-		response := helper.ApiResponse("Register account failed", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errToken, "Register account failed"))
 		return
 	}
 
@@ -64,27 +65,19 @@ func (h *userHandler) LoginUser(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		errors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": errors}
-
-		response := helper.ApiResponse("Login failed", http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperr.Input("Login failed", err))
 		return
 	}
 
-	loggedInUser, roleName, errLogin := h.userService.LoginUser(input)
+	loggedInUser, roleName, errLogin := h.userService.LoginUser(c.Request.Context(), input)
 	if errLogin != nil {
-		errorMessage := gin.H{"errors": errLogin.Error()}
-
-		response := helper.ApiResponse("Login failed", http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperr.Wrap(errLogin, "Login failed"))
 		return
 	}
 
 	token, errToken := h.authService.GenerateToken(loggedInUser.ID)
 	if errToken != nil {
-		response := helper.ApiResponse("Login failed", http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperr.Wrap(errToken, "Login failed"))
 		return
 	}
 	formatter := user.UserJsonFormatter(loggedInUser, roleName, token)
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/role"
+	"nurul-iman-blok-m/webhook"
+	"strconv"
+)
+
+type webhookHandler struct {
+	service webhook.WebhookService
+}
+
+func NewWebhookHandler(service webhook.WebhookService) *webhookHandler {
+	return &webhookHandler{service}
+}
+
+func (h *webhookHandler) CreateEndpoint(c *gin.Context) {
+	var input webhook.EndpointInput
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperr.Input("You must completed field", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionManageWebhook) {
+		c.Error(apperr.Forbidden("You not have access for webhook"))
+		return
+	}
+
+	endpoint, errCreate := h.service.CreateEndpoint(c.Request.Context(), input, currentUser.ID)
+	if errCreate != nil {
+		c.Error(apperr.Wrap(errCreate, "Failed to add webhook"))
+		return
+	}
+
+	response := helper.ApiResponse("Success to add webhook", http.StatusOK, "success", webhook.CreatedEndpointJsonFormatter(endpoint))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *webhookHandler) GetEndpoints(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionManageWebhook) {
+		c.Error(apperr.Forbidden("You not have access for webhook"))
+		return
+	}
+
+	endpoints, err := h.service.GetEndpoints(c.Request.Context())
+	if err != nil {
+		c.Error(apperr.Wrap(err, "Error to get webhooks"))
+		return
+	}
+
+	response := helper.ApiResponse("List Webhook", http.StatusOK, "success", webhook.ListEndpointJsonFormatter(endpoints))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *webhookHandler) DeleteEndpoint(c *gin.Context) {
+	var input webhook.EndpointDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.Input("Delete Failed", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionManageWebhook) {
+		c.Error(apperr.Forbidden("You not have access for webhook"))
+		return
+	}
+
+	errDelete := h.service.DeleteEndpoint(c.Request.Context(), input)
+	if errDelete != nil {
+		c.Error(apperr.Wrap(errDelete, "Delete failed"))
+		return
+	}
+
+	response := helper.ApiResponse("Delete Success", http.StatusOK, "Success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *webhookHandler) GetDeliveries(c *gin.Context) {
+	var input webhook.EndpointDetailInput
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperr.NotFound(apperr.CodeNotFound, "Webhook not found"))
+		return
+	}
+
+	var filter webhook.DeliveryListInput
+	err = c.ShouldBindQuery(&filter)
+	if err != nil {
+		c.Error(apperr.Input("Invalid filter", err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(model.User)
+	if !role.Can(currentUser.Role.RoleName, role.PermissionManageWebhook) {
+		c.Error(apperr.Forbidden("You not have access for webhook"))
+		return
+	}
+
+	page := c.Request.URL.Query().Get("page")
+	perPage := c.Request.URL.Query().Get("per_page")
+
+	paginate := helper.PaginateList(page, perPage)
+
+	deliveries, count, errDeliveries := h.service.GetDeliveries(c.Request.Context(), input, filter, paginate)
+	if errDeliveries != nil {
+		c.Error(apperr.Wrap(errDeliveries, "Error to get deliveries"))
+		return
+	}
+
+	pageString, _ := strconv.Atoi(page)
+	pageSizeString, _ := strconv.Atoi(perPage)
+
+	response := helper.ApiResponseList("List Delivery", http.StatusOK, "success", pageString, pageSizeString, count, webhook.ListDeliveryJsonFormatter(deliveries))
+	c.JSON(http.StatusOK, response)
+}
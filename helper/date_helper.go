@@ -0,0 +1,37 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+)
+
+var monthNames = map[string][]string{
+	"id": {"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+	"en": {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+}
+
+var dayNames = map[string][]string{
+	"id": {"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+	"en": {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+}
+
+// FormatDate writes "Jumat, 7 Maret 2025" style dates, time.Format only knows english names.
+// Unknown locales fall back to Indonesian
+func FormatDate(locale string, value time.Time) string {
+	if _, ok := monthNames[locale]; !ok {
+		locale = "id"
+	}
+	return fmt.Sprintf("%s, %d %s %d", dayNames[locale][value.Weekday()], value.Day(), monthNames[locale][value.Month()-1], value.Year())
+}
+
+// HasLocale tells whether FormatDate knows the names of the locale
+func HasLocale(locale string) bool {
+	_, ok := monthNames[locale]
+	return ok
+}
+
+// StartOfWeek is monday 00:00 of the ISO week value falls in, in the location of value
+func StartOfWeek(value time.Time) time.Time {
+	day := time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, value.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
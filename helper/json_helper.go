@@ -1,7 +1,5 @@
 package helper
 
-import "github.com/go-playground/validator/v10"
-
 type Response struct {
 	Info Info        `json:"info"`
 	Data interface{} `json:"data"`
@@ -16,6 +14,7 @@ type Info struct {
 	Message string `json:"message"`
 	Code    int    `json:"code"`
 	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
 }
 
 type InfoList struct {
@@ -42,6 +41,13 @@ func ApiResponse(message string, code int, status string, data interface{}) Resp
 	return jsonResponse
 }
 
+// ApiErrorResponse adds the machine readable error code, e.g. "announcement_not_found"
+func ApiErrorResponse(message string, code int, errorCode string, data interface{}) Response {
+	jsonResponse := ApiResponse(message, code, "error", data)
+	jsonResponse.Info.Error = errorCode
+	return jsonResponse
+}
+
 func ApiResponseList(message string, code int, status string, page int, pageSize int, count int, data interface{}) ResponseList {
 	info := InfoList{
 		Message: message,
@@ -59,13 +65,3 @@ func ApiResponseList(message string, code int, status string, page int, pageSize
 
 	return jsonResponse
 }
-
-func FormatValidationError(err error) []string {
-	var errors []string
-
-	for _, e := range err.(validator.ValidationErrors) {
-		errors = append(errors, e.Error())
-	}
-
-	return errors
-}
@@ -0,0 +1,104 @@
+package helper
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+	"golang.org/x/text/language"
+	"reflect"
+	"strings"
+)
+
+// validation messages exist in these locales, the first one is the fallback
+var validationLocales = []string{"id", "en"}
+
+var validationLanguages = language.NewMatcher([]language.Tag{language.Indonesian, language.English})
+
+var translators = ut.New(id.New(), id.New(), en.New())
+
+// invalidBody is the message for requests that never reached the validator, like malformed JSON
+const invalidBody = "invalid_body"
+
+// SetupValidation makes the validator gin binds with report the field names clients send and registers
+// the Indonesian and English messages. Call it once before the router starts serving
+func SetupValidation() error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: gin does not bind with go-playground/validator")
+	}
+	validate.RegisterTagNameFunc(fieldName)
+
+	idTranslator, _ := translators.GetTranslator("id")
+	err := idTranslations.RegisterDefaultTranslations(validate, idTranslator)
+	if err != nil {
+		return err
+	}
+	err = idTranslator.Add(invalidBody, "format data tidak valid", false)
+	if err != nil {
+		return err
+	}
+	// the Indonesian set has no message for datetime, publish_at and expire_at use it
+	err = validate.RegisterTranslation("datetime", idTranslator, func(translator ut.Translator) error {
+		return translator.Add("datetime", "{0} tidak sesuai dengan format {1}", false)
+	}, func(translator ut.Translator, fe validator.FieldError) string {
+		message, _ := translator.T(fe.Tag(), fe.Field(), fe.Param())
+		return message
+	})
+	if err != nil {
+		return err
+	}
+
+	enTranslator, _ := translators.GetTranslator("en")
+	err = enTranslations.RegisterDefaultTranslations(validate, enTranslator)
+	if err != nil {
+		return err
+	}
+	return enTranslator.Add(invalidBody, "malformed request body", false)
+}
+
+// fieldName is the json key of a field, or its form or uri key for inputs that are not JSON
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// ValidationLocale picks the best supported locale from an Accept-Language header, Indonesian when none fits
+func ValidationLocale(acceptLanguage string) string {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := validationLanguages.Match(tags...)
+	return validationLocales[index]
+}
+
+// FormatValidationError keys the message of every failed field by the name the client sent,
+// nested fields keep their path like "items[0].title"
+func FormatValidationError(err error, locale string) map[string]string {
+	translator, _ := translators.GetTranslator(locale)
+	messages := map[string]string{}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		message, _ := translator.T(invalidBody)
+		messages["body"] = message
+		return messages
+	}
+	for _, e := range validationErrors {
+		// the namespace starts with the struct name, e.g. AnnouncementInput.title
+		field := e.Namespace()
+		if index := strings.Index(field, "."); index >= 0 {
+			field = field[index+1:]
+		}
+		messages[field] = e.Translate(translator)
+	}
+
+	return messages
+}
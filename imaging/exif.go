@@ -0,0 +1,118 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// exifOrientation reads tag 0x0112 from the APP1 segment of a jpeg, 1 means upright or unknown
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		if marker == 0xDA || length < 2 || offset+2+length > len(data) {
+			// start of scan, metadata is always before it
+			return 1
+		}
+
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && len(segment) > 14 && string(segment[:6]) == "Exif\x00\x00" {
+			return orientationFromTiff(segment[6:])
+		}
+		offset += 2 + length
+	}
+
+	return 1
+}
+
+func orientationFromTiff(tiff []byte) int {
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// applyOrientation rotates and flips the pixels so the image looks upright without its EXIF block
+func applyOrientation(source image.Image, orientation int) image.Image {
+	if orientation <= 1 {
+		return source
+	}
+
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if orientation >= 5 {
+		width, height = height, width
+	}
+
+	// work on the raw buffer, At and Set allocate a color for every pixel
+	pixels, ok := source.(*image.RGBA)
+	if !ok || pixels.Rect.Min != (image.Point{}) {
+		pixels = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(pixels, pixels.Bounds(), source, bounds.Min, draw.Src)
+	}
+
+	target := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < bounds.Dy(); y++ {
+		row := pixels.Pix[y*pixels.Stride:]
+		for x := 0; x < bounds.Dx(); x++ {
+			targetX, targetY := x, y
+			switch orientation {
+			case 2:
+				targetX = bounds.Dx() - 1 - x
+			case 3:
+				targetX, targetY = bounds.Dx()-1-x, bounds.Dy()-1-y
+			case 4:
+				targetY = bounds.Dy() - 1 - y
+			case 5:
+				targetX, targetY = y, x
+			case 6:
+				targetX, targetY = bounds.Dy()-1-y, x
+			case 7:
+				targetX, targetY = bounds.Dy()-1-y, bounds.Dx()-1-x
+			case 8:
+				targetX, targetY = y, bounds.Dx()-1-x
+			}
+			offset := targetY*target.Stride + targetX*4
+			copy(target.Pix[offset:offset+4], row[x*4:x*4+4])
+		}
+	}
+
+	return target
+}
@@ -0,0 +1,161 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxUploadSize is the largest file accepted before compression, phone photos are usually 3-8MB
+const MaxUploadSize = 10 << 20
+
+// MaxPixels bounds the decoded size, a small file can still declare dimensions that need gigabytes of memory
+const MaxPixels = 40_000_000
+
+const (
+	VariantThumbnail = "thumbnail"
+	VariantMedium    = "medium"
+	VariantLarge     = "large"
+)
+
+var ErrUnsupportedType = errors.New("only jpeg, png and webp images are allowed")
+var ErrTooLarge = errors.New("image too large")
+var ErrTooManyPixels = errors.New("image dimensions too large")
+
+// variant name and the longest side in pixels, smaller images are never upscaled
+var variantSizes = []struct {
+	Name    string
+	MaxSide int
+}{
+	{VariantThumbnail, 320},
+	{VariantMedium, 800},
+	{VariantLarge, 1600},
+}
+
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+type Variant struct {
+	Name        string
+	Body        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// DetectContentType sniffs the magic bytes and ignores the filename and client supplied header
+func DetectContentType(header []byte) (string, error) {
+	contentType := http.DetectContentType(header)
+	if !allowedTypes[contentType] {
+		return contentType, ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// Process validates an uploaded image and returns re-encoded thumbnail, medium and large variants.
+// Re-encoding drops every metadata block, EXIF orientation is applied to the pixels first.
+func Process(file io.Reader) ([]Variant, error) {
+	data, err := io.ReadAll(io.LimitReader(file, MaxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxUploadSize {
+		return nil, ErrTooLarge
+	}
+
+	contentType, err := DetectContentType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the header is enough to know the dimensions, check them before allocating the pixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > MaxPixels/config.Height {
+		return nil, ErrTooManyPixels
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType == "image/jpeg" {
+		source = applyOrientation(source, exifOrientation(data))
+	}
+
+	// png keeps transparency, jpeg and webp are stored as jpeg since there is no webp encoder
+	outputType, extension := "image/jpeg", "jpg"
+	if contentType == "image/png" {
+		outputType, extension = "image/png", "png"
+	}
+
+	var variants []Variant
+	for _, size := range variantSizes {
+		resized := resize(source, size.MaxSide)
+
+		var buffer bytes.Buffer
+		if outputType == "image/png" {
+			err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buffer, resized)
+		} else {
+			err = jpeg.Encode(&buffer, resized, &jpeg.Options{Quality: 82})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, Variant{
+			Name:        size.Name,
+			Body:        buffer.Bytes(),
+			ContentType: outputType,
+			Extension:   extension,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+		})
+	}
+
+	return variants, nil
+}
+
+func resize(source image.Image, maxSide int) image.Image {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= maxSide && height <= maxSide {
+		// still copied so every variant is re-encoded from plain pixels
+		target := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(target, target.Bounds(), source, bounds.Min, draw.Src)
+		return target
+	}
+
+	if width >= height {
+		height = height * maxSide / width
+		width = maxSide
+	} else {
+		width = width * maxSide / height
+		height = maxSide
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	target := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(target, target.Bounds(), source, bounds, draw.Src, nil)
+	return target
+}
@@ -0,0 +1,178 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	err := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func encodeJPEG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	err := jpeg.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// pngHeader is a png that stops after its IHDR chunk, enough for DecodeConfig to read the declared size
+func pngHeader(width uint32, height uint32) []byte {
+	chunk := make([]byte, 17)
+	copy(chunk, "IHDR")
+	binary.BigEndian.PutUint32(chunk[4:], width)
+	binary.BigEndian.PutUint32(chunk[8:], height)
+	chunk[12] = 8 // bit depth
+	chunk[13] = 6 // rgba
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, chunk...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+}
+
+func TestProcess(t *testing.T) {
+	type size struct{ width, height int }
+
+	tests := []struct {
+		name     string
+		data     func(t *testing.T) []byte
+		wantType string
+		wantSize []size
+		wantErr  error
+	}{
+		{
+			name:     "small png is not upscaled",
+			data:     func(t *testing.T) []byte { return encodePNG(t, 200, 100) },
+			wantType: "image/png",
+			wantSize: []size{{200, 100}, {200, 100}, {200, 100}},
+		},
+		{
+			name:     "landscape jpeg",
+			data:     func(t *testing.T) []byte { return encodeJPEG(t, 2000, 1000) },
+			wantType: "image/jpeg",
+			wantSize: []size{{320, 160}, {800, 400}, {1600, 800}},
+		},
+		{
+			name:     "portrait png",
+			data:     func(t *testing.T) []byte { return encodePNG(t, 500, 1000) },
+			wantType: "image/png",
+			wantSize: []size{{160, 320}, {400, 800}, {500, 1000}},
+		},
+		{
+			name:    "not an image",
+			data:    func(t *testing.T) []byte { return []byte("<html><body>hello</body></html>") },
+			wantErr: ErrUnsupportedType,
+		},
+		{
+			name:    "gif is not allowed",
+			data:    func(t *testing.T) []byte { return []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;") },
+			wantErr: ErrUnsupportedType,
+		},
+		{
+			name: "file over the upload limit",
+			data: func(t *testing.T) []byte {
+				return append(encodePNG(t, 1, 1), make([]byte, MaxUploadSize)...)
+			},
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "declared dimensions over the pixel limit",
+			data:    func(t *testing.T) []byte { return pngHeader(50_000, 50_000) },
+			wantErr: ErrTooManyPixels,
+		},
+		{
+			name:    "one long side",
+			data:    func(t *testing.T) []byte { return pngHeader(1_000_000, 41) },
+			wantErr: ErrTooManyPixels,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variants, err := Process(bytes.NewReader(test.data(t)))
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got error %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(variants) != len(test.wantSize) {
+				t.Fatalf("got %d variants, want %d", len(variants), len(test.wantSize))
+			}
+			for i, variant := range variants {
+				if variant.ContentType != test.wantType {
+					t.Errorf("%s: got type %q, want %q", variant.Name, variant.ContentType, test.wantType)
+				}
+				if variant.Width != test.wantSize[i].width || variant.Height != test.wantSize[i].height {
+					t.Errorf("%s: got %dx%d, want %dx%d", variant.Name, variant.Width, variant.Height, test.wantSize[i].width, test.wantSize[i].height)
+				}
+				config, _, err := image.DecodeConfig(bytes.NewReader(variant.Body))
+				if err != nil {
+					t.Fatalf("%s: %v", variant.Name, err)
+				}
+				if config.Width != variant.Width || config.Height != variant.Height {
+					t.Errorf("%s: encoded as %dx%d", variant.Name, config.Width, config.Height)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+
+	// a 3x2 image with a red pixel in the top left corner
+	source := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	source.SetRGBA(0, 0, red)
+
+	tests := []struct {
+		orientation int
+		wantWidth   int
+		wantHeight  int
+		wantRed     image.Point
+	}{
+		{1, 3, 2, image.Pt(0, 0)},
+		{2, 3, 2, image.Pt(2, 0)},
+		{3, 3, 2, image.Pt(2, 1)},
+		{4, 3, 2, image.Pt(0, 1)},
+		{5, 2, 3, image.Pt(0, 0)},
+		{6, 2, 3, image.Pt(1, 0)},
+		{7, 2, 3, image.Pt(1, 2)},
+		{8, 2, 3, image.Pt(0, 2)},
+	}
+
+	for _, test := range tests {
+		result := applyOrientation(source, test.orientation)
+		bounds := result.Bounds()
+		if bounds.Dx() != test.wantWidth || bounds.Dy() != test.wantHeight {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", test.orientation, bounds.Dx(), bounds.Dy(), test.wantWidth, test.wantHeight)
+			continue
+		}
+		if result.At(test.wantRed.X, test.wantRed.Y) != color.Color(red) {
+			t.Errorf("orientation %d: red pixel not at %v", test.orientation, test.wantRed)
+		}
+	}
+}
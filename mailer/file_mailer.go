@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileName = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer writes every email as an .eml file into dir, open them with any mail client
+func NewFileMailer(dir string, from string) *fileMailer {
+	return &fileMailer{dir, from}
+}
+
+func (m *fileMailer) Send(ctx context.Context, email Email) error {
+	err := os.MkdirAll(m.dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), unsafeFileName.ReplaceAllString(email.To, "_"))
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, email), 0o644)
+}
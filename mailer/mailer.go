@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+)
+
+type Email struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Mailer hands a rendered email to the outside world, SMTP in production and files in development
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// buildMessage writes a multipart/alternative message, clients pick html and fall back to text
+func buildMessage(from string, email Email) []byte {
+	boundary := make([]byte, 12)
+	_, _ = rand.Read(boundary)
+	marker := "alt-" + hex.EncodeToString(boundary)
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", email.To)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", marker)
+
+	writePart(&message, marker, "text/plain; charset=utf-8", email.Text)
+	writePart(&message, marker, "text/html; charset=utf-8", email.HTML)
+	fmt.Fprintf(&message, "--%s--\r\n", marker)
+
+	return message.Bytes()
+}
+
+func writePart(message *bytes.Buffer, marker string, contentType string, body string) {
+	fmt.Fprintf(message, "--%s\r\n", marker)
+	fmt.Fprintf(message, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(message, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	writer := quotedprintable.NewWriter(message)
+	_, _ = writer.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	_ = writer.Close()
+	message.WriteString("\r\n")
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import "time"
+
+// Message is an email waiting to be rendered, Key makes enqueueing the same message twice a no-op
+type Message struct {
+	Key      string
+	To       string
+	Locale   string
+	Template string
+	Data     interface{}
+}
+
+type InvitationData struct {
+	Name        string
+	InviterName string
+	RoleName    string
+	URL         string
+	ExpiresAt   time.Time
+}
+
+type PasswordResetData struct {
+	Name      string
+	URL       string
+	ExpiresAt time.Time
+}
+
+type DonationReceiptData struct {
+	Name       string
+	Number     string
+	Amount     string
+	Purpose    string
+	ReceivedAt time.Time
+}
+
+type ReviewDecisionData struct {
+	Name         string
+	Title        string
+	Approved     bool
+	Comment      string
+	ReviewerName string
+}
+
+type DigestData struct {
+	Name          string
+	WeekStart     time.Time
+	Announcements []DigestAnnouncement
+	Rundowns      []DigestRundown
+}
+
+type DigestAnnouncement struct {
+	Title string
+	URL   string
+}
+
+type DigestRundown struct {
+	Title   string
+	Speaker string
+	StartAt time.Time
+}
@@ -0,0 +1,90 @@
+package mailer
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type MailRepository interface {
+	CreateEmail(ctx context.Context, tx *gorm.DB, email model.EmailOutbox) (model.EmailOutbox, bool, error)
+	SaveEmail(ctx context.Context, email model.EmailOutbox) (model.EmailOutbox, error)
+	GetDueEmails(ctx context.Context, now time.Time, limit int) ([]model.EmailOutbox, error)
+	CountPendingEmails(ctx context.Context) (int64, error)
+	GetDigestRecipients(ctx context.Context) ([]model.User, error)
+	GetAnnouncementsSince(ctx context.Context, since time.Time) ([]model.Announcement, error)
+	GetRundownsOn(ctx context.Context, dates []string) ([]model.StudyRundown, error)
+}
+
+type mailRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *mailRepository {
+	return &mailRepository{db}
+}
+
+// CreateEmail writes through tx when the email belongs to a bigger transaction, so it is only sent
+// once that transaction commits. It reports false when an email with the same key was already queued
+func (r *mailRepository) CreateEmail(ctx context.Context, tx *gorm.DB, email model.EmailOutbox) (model.EmailOutbox, bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	result := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&email)
+	if result.Error != nil {
+		return email, false, result.Error
+	}
+	return email, result.RowsAffected == 1, nil
+}
+
+func (r *mailRepository) SaveEmail(ctx context.Context, email model.EmailOutbox) (model.EmailOutbox, error) {
+	err := r.db.WithContext(ctx).Save(&email).Error
+	if err != nil {
+		return email, err
+	}
+	return email, nil
+}
+
+func (r *mailRepository) CountPendingEmails(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.EmailOutbox{}).Where("status = ?", StatusPending).Count(&count).Error
+	return count, err
+}
+
+func (r *mailRepository) GetDueEmails(ctx context.Context, now time.Time, limit int) ([]model.EmailOutbox, error) {
+	var emails []model.EmailOutbox
+	err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", StatusPending, now).Order("next_attempt_at asc").Limit(limit).Find(&emails).Error
+	if err != nil {
+		return emails, err
+	}
+	return emails, nil
+}
+
+func (r *mailRepository) GetDigestRecipients(ctx context.Context) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).Where("email <> ''").Find(&users).Error
+	if err != nil {
+		return users, err
+	}
+	return users, nil
+}
+
+func (r *mailRepository) GetAnnouncementsSince(ctx context.Context, since time.Time) ([]model.Announcement, error) {
+	var announcements []model.Announcement
+	err := r.db.WithContext(ctx).Where("COALESCE(publish_at, created_at) >= ?", since).Order("COALESCE(publish_at, created_at) desc").Find(&announcements).Error
+	if err != nil {
+		return announcements, err
+	}
+	return announcements, nil
+}
+
+func (r *mailRepository) GetRundownsOn(ctx context.Context, dates []string) ([]model.StudyRundown, error) {
+	var rundowns []model.StudyRundown
+	err := r.db.WithContext(ctx).Preload("User").Where("schedule_date IN ?", dates).Find(&rundowns).Error
+	if err != nil {
+		return rundowns, err
+	}
+	return rundowns, nil
+}
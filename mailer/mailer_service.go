@@ -0,0 +1,270 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"nurul-iman-blok-m/announcement"
+	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/study_rundown"
+	"nurul-iman-blok-m/worker"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+
+	// MaxAttempts gives up on an address after roughly a day of retries
+	MaxAttempts = 8
+
+	// DigestWeekday is when the weekly digest goes out, DigestHour in the mosque's time zone
+	DigestWeekday = time.Friday
+	DigestHour    = 6
+
+	baseBackoff  = time.Minute
+	maxBackoff   = 12 * time.Hour
+	batchSize    = 50
+	sendTimeout  = 30 * time.Second
+	maxLogLength = 2000
+)
+
+type MailService interface {
+	Enqueue(ctx context.Context, tx *gorm.DB, message Message) (model.EmailOutbox, bool, error)
+	ProcessOutbox(ctx context.Context) (int, error)
+	SendWeeklyDigest(ctx context.Context) (int, error)
+	CountPending(ctx context.Context) (int64, error)
+}
+
+type mailService struct {
+	repository MailRepository
+	mailer     Mailer
+	siteURL    string
+	location   *time.Location
+}
+
+func NewService(repository MailRepository, mailer Mailer, siteURL string, location *time.Location) *mailService {
+	return &mailService{repository, mailer, siteURL, location}
+}
+
+// Enqueue renders the message into the outbox, pass the transaction handle of the change that
+// triggers the email so a rollback also drops it. Nothing is sent here, the outbox worker does that.
+// It reports false when an email with the same key was queued before
+func (s *mailService) Enqueue(ctx context.Context, tx *gorm.DB, message Message) (model.EmailOutbox, bool, error) {
+	rendered, err := Render(message.Locale, message.Template, message.Data)
+	if err != nil {
+		return model.EmailOutbox{}, false, err
+	}
+
+	email := model.EmailOutbox{
+		Recipient:     message.To,
+		Template:      message.Template,
+		Subject:       rendered.Subject,
+		HTML:          rendered.HTML,
+		Text:          rendered.Text,
+		Status:        StatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if message.Key != "" {
+		email.Key = &message.Key
+	}
+
+	return s.repository.CreateEmail(ctx, tx, email)
+}
+
+// CountPending is the outbox backlog, including emails waiting for a retry
+func (s *mailService) CountPending(ctx context.Context) (int64, error) {
+	return s.repository.CountPendingEmails(ctx)
+}
+
+// ProcessOutbox sends the emails whose next attempt is due and reschedules the failed ones
+func (s *mailService) ProcessOutbox(ctx context.Context) (int, error) {
+	emails, err := s.repository.GetDueEmails(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, email := range emails {
+		email = s.send(ctx, email)
+		_, err = s.repository.SaveEmail(ctx, email)
+		if err != nil {
+			return sent, err
+		}
+		if email.Status == StatusSent {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *mailService) send(ctx context.Context, email model.EmailOutbox) model.EmailOutbox {
+	email.Attempts++
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	err := s.mailer.Send(ctx, Email{To: email.Recipient, Subject: email.Subject, HTML: email.HTML, Text: email.Text})
+	if err == nil {
+		now := time.Now()
+		email.Status = StatusSent
+		email.LastError = ""
+		email.SentAt = &now
+		return email
+	}
+
+	email.LastError = err.Error()
+	if len(email.LastError) > maxLogLength {
+		email.LastError = email.LastError[:maxLogLength]
+	}
+	if email.Attempts >= MaxAttempts {
+		email.Status = StatusFailed
+		return email
+	}
+	email.NextAttemptAt = time.Now().Add(Backoff(email.Attempts))
+	return email
+}
+
+// Backoff doubles the wait after every failed attempt: 1m, 2m, 4m ... capped at 12h
+func Backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return wait
+}
+
+// SendWeeklyDigest queues the digest of the current week for every user with an email address.
+// It only acts from DigestWeekday DigestHour on, the per week key keeps a second run from sending twice
+func (s *mailService) SendWeeklyDigest(ctx context.Context) (int, error) {
+	now := time.Now().In(s.location)
+	weekStart := helper.StartOfWeek(now)
+	sendAt := weekStart.AddDate(0, 0, (int(DigestWeekday)+6)%7).Add(DigestHour * time.Hour)
+	if now.Before(sendAt) {
+		return 0, nil
+	}
+
+	// the scheduler runs every hour and every run goes through all recipients, the per user key skips
+	// the ones already queued this week, so a run that stopped halfway is finished by the next one
+	year, week := now.ISOWeek()
+	keyPrefix := fmt.Sprintf("digest:%d-W%02d:", year, week)
+
+	announcements, err := s.digestAnnouncements(ctx, now.AddDate(0, 0, -7))
+	if err != nil {
+		return 0, err
+	}
+	rundowns, err := s.digestRundowns(ctx, now, now.AddDate(0, 0, 7))
+	if err != nil {
+		return 0, err
+	}
+	if len(announcements) == 0 && len(rundowns) == 0 {
+		return 0, nil
+	}
+
+	users, err := s.repository.GetDigestRecipients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, user := range users {
+		_, created, err := s.Enqueue(ctx, nil, Message{
+			Key:      keyPrefix + strconv.FormatUint(uint64(user.ID), 10),
+			To:       user.Email,
+			Locale:   DefaultLocale,
+			Template: TemplateWeeklyDigest,
+			Data: DigestData{
+				Name:          user.Name,
+				WeekStart:     weekStart,
+				Announcements: announcements,
+				Rundowns:      rundowns,
+			},
+		})
+		if err != nil {
+			return queued, err
+		}
+		if created {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+func (s *mailService) digestAnnouncements(ctx context.Context, since time.Time) ([]DigestAnnouncement, error) {
+	items, err := s.repository.GetAnnouncementsSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var announcements []DigestAnnouncement
+	for _, item := range items {
+		if !announcement.IsPublic(item) {
+			continue
+		}
+		announcements = append(announcements, DigestAnnouncement{
+			Title: item.Title,
+			URL:   strings.TrimRight(s.siteURL, "/") + "/announcements/" + item.Slug,
+		})
+	}
+	return announcements, nil
+}
+
+func (s *mailService) digestRundowns(ctx context.Context, from time.Time, to time.Time) ([]DigestRundown, error) {
+	var dates []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, study_rundown.ScheduleDates(day)...)
+	}
+
+	items, err := s.repository.GetRundownsOn(ctx, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	var rundowns []DigestRundown
+	for _, item := range items {
+		start, ok := study_rundown.StartTime(item, s.location)
+		if !ok || start.Before(from) || start.After(to) {
+			continue
+		}
+		rundowns = append(rundowns, DigestRundown{Title: item.Title, Speaker: item.User.Name, StartAt: start})
+	}
+	sort.Slice(rundowns, func(i, j int) bool {
+		return rundowns[i].StartAt.Before(rundowns[j].StartAt)
+	})
+	return rundowns, nil
+}
+
+func StartOutboxWorker(jobs *worker.Group, service MailService, interval time.Duration) {
+	jobs.Every(interval, false, func(ctx context.Context) {
+		sent, err := service.ProcessOutbox(ctx)
+		if err != nil {
+			log.Printf("email outbox failed: %v", err)
+			return
+		}
+		if sent > 0 {
+			log.Printf("email outbox sent %d emails", sent)
+		}
+	})
+}
+
+func StartDigestScheduler(jobs *worker.Group, service MailService, interval time.Duration) {
+	jobs.Every(interval, false, func(ctx context.Context) {
+		queued, err := service.SendWeeklyDigest(ctx)
+		if err != nil {
+			log.Printf("weekly digest failed: %v", err)
+			return
+		}
+		if queued > 0 {
+			log.Printf("weekly digest queued for %d users", queued)
+		}
+	})
+}
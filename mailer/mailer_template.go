@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"nurul-iman-blok-m/helper"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+const (
+	LocaleIndonesian = "id"
+	LocaleEnglish    = "en"
+	DefaultLocale    = LocaleIndonesian
+
+	TemplateInvitation      = "invitation"
+	TemplatePasswordReset   = "password_reset"
+	TemplateDonationReceipt = "donation_receipt"
+	TemplateWeeklyDigest    = "weekly_digest"
+	TemplateReviewDecision  = "review_decision"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Render builds the email of a template in the given locale, unknown locales fall back to Indonesian.
+// Every <name>.txt defines a "subject" block next to the text body, <name>.html holds the html body
+func Render(locale string, name string, data interface{}) (Email, error) {
+	if !helper.HasLocale(locale) {
+		locale = DefaultLocale
+	}
+	funcs := map[string]interface{}{
+		"date":     func(value time.Time) string { return helper.FormatDate(locale, value) },
+		"datetime": func(value time.Time) string { return helper.FormatDate(locale, value) + " " + value.Format("15:04") },
+	}
+
+	text, err := texttemplate.New(name+".txt").Funcs(funcs).ParseFS(templateFiles, fmt.Sprintf("templates/%s/%s.txt", locale, name))
+	if err != nil {
+		return Email{}, err
+	}
+	html, err := htmltemplate.New(name+".html").Funcs(funcs).ParseFS(templateFiles, "templates/layout.html", fmt.Sprintf("templates/%s/%s.html", locale, name))
+	if err != nil {
+		return Email{}, err
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+	err = text.ExecuteTemplate(&subject, "subject", data)
+	if err != nil {
+		return Email{}, err
+	}
+	err = text.Execute(&textBody, data)
+	if err != nil {
+		return Email{}, err
+	}
+	err = html.ExecuteTemplate(&htmlBody, "layout", data)
+	if err != nil {
+		return Email{}, err
+	}
+
+	return Email{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    htmlBody.String(),
+		Text:    strings.TrimSpace(textBody.String()) + "\n",
+	}, nil
+}
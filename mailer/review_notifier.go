@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/review"
+)
+
+type reviewNotifier struct {
+	service MailService
+}
+
+// NewReviewNotifier emails the author of a reviewed item, it satisfies review.Notifier
+func NewReviewNotifier(service MailService) *reviewNotifier {
+	return &reviewNotifier{service}
+}
+
+// NotifyReviewDecision queues the email in the transaction that stores the decision
+func (n *reviewNotifier) NotifyReviewDecision(ctx context.Context, tx *gorm.DB, decided model.Review) error {
+	if decided.Author.Email == "" {
+		return nil
+	}
+
+	_, _, err := n.service.Enqueue(ctx, tx, Message{
+		Key:      fmt.Sprintf("review:%d:%s", decided.ID, decided.Status),
+		To:       decided.Author.Email,
+		Locale:   DefaultLocale,
+		Template: TemplateReviewDecision,
+		Data: ReviewDecisionData{
+			Name:         decided.Author.Name,
+			Title:        decided.Title,
+			Approved:     decided.Status == review.StatusApproved,
+			Comment:      decided.Comment,
+			ReviewerName: decided.Reviewer.Name,
+		},
+	})
+	return err
+}
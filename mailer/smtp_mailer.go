@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+type smtpMailer struct {
+	address string
+	auth    smtp.Auth
+	from    string
+}
+
+// NewSMTPMailer sends through a relay, STARTTLS is used whenever the server offers it
+func NewSMTPMailer(host string, port int, username string, password string, from string) *smtpMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{net.JoinHostPort(host, strconv.Itoa(port)), auth, from}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, email Email) error {
+	// the envelope wants the bare address, the From header keeps the display name
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.address, m.auth, sender.Address, []string{email.To}, buildMessage(m.from, email))
+}
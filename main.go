@@ -2,72 +2,240 @@ package main
 
 import (
 	"context"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/joho/godotenv"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"log"
-	"net/http"
 	"nurul-iman-blok-m/announcement"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/audit"
 	"nurul-iman-blok-m/auth"
+	"nurul-iman-blok-m/broadcast"
+	"nurul-iman-blok-m/config"
 	"nurul-iman-blok-m/database"
 	"nurul-iman-blok-m/handler"
 	"nurul-iman-blok-m/helper"
+	"nurul-iman-blok-m/mailer"
+	"nurul-iman-blok-m/media"
+	"nurul-iman-blok-m/metrics"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/push"
+	"nurul-iman-blok-m/recording"
+	"nurul-iman-blok-m/review"
 	"nurul-iman-blok-m/role"
+	"nurul-iman-blok-m/slug"
+	"nurul-iman-blok-m/storage"
 	"nurul-iman-blok-m/study_rundown"
+	"nurul-iman-blok-m/tracing"
+	"nurul-iman-blok-m/trash"
+	"nurul-iman-blok-m/upload"
 	"nurul-iman-blok-m/user"
+	"nurul-iman-blok-m/webhook"
+	"nurul-iman-blok-m/worker"
 	"os"
 	"strings"
+	"time"
 )
 
+const usage = `usage: nurul-iman-blok-m <command> [flags]
+
+commands:
+  serve           run the api, the default when no command is given
+  migrate         apply, roll back or create database migrations
+  seed            create the default roles, plus demo content with -demo
+  create-admin    make an account super-admin, e.g. create-admin -email takmir@example.org
+  rotate-secret   generate a new API_SECRET, tokens signed with the old one stay valid`
+
 func main() {
-	db := database.Db()
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		serve(args)
+	case "migrate":
+		runMigrate(args)
+	case "seed":
+		runSeed(args)
+	case "create-admin":
+		runCreateAdmin(args)
+	case "rotate-secret":
+		runRotateSecret(args)
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	allowPending := flags.Bool("allow-pending-migrations", false, "serve even when the database has pending migrations")
+	flags.Parse(args)
+
+	cfg, errConfig := config.Load()
+	if errConfig != nil {
+		log.Fatal(errConfig)
+	}
+
+	ctx := context.Background()
+	flushTracing, errTracing := tracing.Setup(ctx, cfg.Tracing, cfg.App.Env)
+	if errTracing != nil {
+		log.Fatal(errTracing)
+	}
+
+	db := database.Db(cfg.Database)
+	checkMigrations(db, cfg.App.Env, *allowPending)
+	errMetrics := metrics.InstrumentGORM(db)
+	if errMetrics != nil {
+		log.Fatal(errMetrics)
+	}
+	errTracing = tracing.InstrumentGORM(db)
+	if errTracing != nil {
+		log.Fatal(errTracing)
+	}
 
 	userRepository := user.NewRepository(db)
 	roleRepository := role.NewRepository(db)
 	announcementRepository := announcement.NewRepositoryAnnouncement(db)
 	studyRundownRepository := study_rundown.NewRepository(db)
+	trashRepository := trash.NewRepository(db)
+	auditRepository := audit.NewRepository(db)
+	reviewRepository := review.NewRepository(db)
+	uploadRepository := upload.NewRepository(db)
+	mediaRepository := media.NewRepository(db)
+	recordingRepository := recording.NewRepository(db)
+	webhookRepository := webhook.NewRepository(db)
+	pushRepository := push.NewRepository(db)
+	mailRepository := mailer.NewRepository(db)
+	broadcastRepository := broadcast.NewRepository(db)
+	slugRepository := slug.NewRepository(db)
 
 	userService := user.NewService(userRepository)
-	authService := auth.NewService()
+	authService := auth.NewService(cfg.App.Secret, cfg.App.PreviousSecret)
 	roleService := role.NewRoleService(roleRepository)
-	announcementService := announcement.NewServiceAnnouncement(announcementRepository)
-	studyRundownService := study_rundown.NewService(studyRundownRepository)
+	slugService := slug.NewService(slugRepository)
+	webhookService := webhook.NewService(webhookRepository)
+	studyRundownService := study_rundown.NewService(studyRundownRepository, webhookService)
+	auditService := audit.NewService(auditRepository)
 
-	userHandler := handler.NewUserHandler(userService, authService)
-	roleHandler := handler.NewRoleHandler(roleService)
-	studyRundownHandler := handler.NewHandlerStudyRundown(studyRundownService)
+	userHandler := handler.NewUserHandler(userService, authService, auditService)
+	roleHandler := handler.NewRoleHandler(roleService, auditService)
+	studyRundownHandler := handler.NewHandlerStudyRundown(studyRundownService, auditService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
 
-	// load env variables
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
+	// background jobs stop with the server, see runServer
+	jobs := worker.NewGroup()
 
 	// setup gin app
+	errValidation := helper.SetupValidation()
+	if errValidation != nil {
+		log.Fatal(errValidation)
+	}
 	router := gin.Default()
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(tracing.SkipProbes)))
+	router.Use(metrics.Middleware())
+	router.Use(apperr.Middleware())
 	router.Use(cors.Default())
 	router.Static("/images", "./images")
 
-	// setup s3 uploader
-	cfg, errS3 := config.LoadDefaultConfig(context.TODO())
-	if errS3 != nil {
-		log.Printf("error: %v", errS3)
-		return
+	// trashed items are purged permanently after the retention period
+	trashService := trash.NewService(trashRepository, time.Duration(cfg.Trash.RetentionDays)*24*time.Hour)
+	trashHandler := handler.NewTrashHandler(trashService, auditService)
+	trash.StartPurgeScheduler(jobs, trashService, time.Hour)
+
+	appURL := cfg.App.URL
+	siteURL := cfg.App.SiteURL
+
+	// uploads and banners go to S3, or to ./uploads when STORAGE_DRIVER=local for development
+	var fileStorage storage.Storage
+	var localStorage *storage.LocalStorage
+	if cfg.Storage.Driver == "local" {
+		localStorage = storage.NewLocalStorage(cfg.Storage.LocalDir, appURL+"/uploads", appURL+"/api/v1/uploads/local", cfg.App.Secret)
+		fileStorage = localStorage
+		router.Static("/uploads", cfg.Storage.LocalDir)
+	} else {
+		s3Client, errS3 := storage.NewS3Client(ctx, cfg.AWS)
+		if errS3 != nil {
+			log.Fatal(errS3)
+		}
+		fileStorage = storage.NewS3Storage(s3Client, cfg.AWS.Bucket, cfg.AWS.PublicURL)
 	}
-
-	client := s3.NewFromConfig(cfg)
-	uploader := manager.NewUploader(client)
-
-	announcementHandler := handler.NewHandlerAnnouncement(announcementService, *uploader, *configS3())
+	fileStorage = storage.Traced(fileStorage)
+	// files nothing refers to anymore are removed from storage once a day
+	mediaService := media.NewService(mediaRepository, fileStorage)
+	mediaHandler := handler.NewMediaHandler(mediaService, auditService)
+	media.StartGarbageCollector(jobs, mediaService, 24*time.Hour)
+
+	uploadService := upload.NewService(uploadRepository, fileStorage, mediaService)
+	uploadHandler := handler.NewUploadHandler(uploadService, localStorage)
+
+	recordingService := recording.NewService(recordingRepository, uploadService, mediaService)
+	recordingHandler := handler.NewRecordingHandler(recordingService, recording.PodcastInfo{
+		Title:       "Kajian Masjid Nurul Iman Blok M",
+		Link:        appURL,
+		Description: "Rekaman kajian Masjid Nurul Iman Blok M",
+		Language:    "id",
+		Author:      "Masjid Nurul Iman Blok M",
+		OwnerEmail:  cfg.Podcast.OwnerEmail,
+		ImageURL:    cfg.Podcast.ImageURL,
+		Category:    "Religion & Spirituality",
+	}, auditService)
+
+	pushService := push.NewService(pushRepository, pushProviders(cfg.Push, cfg.App.Env), jakartaLocation())
+	pushHandler := handler.NewPushHandler(pushService)
+	push.StartReminderScheduler(jobs, pushService, time.Minute)
+	push.StartDispatchWorker(jobs, pushService, 15*time.Second)
+
+	announcementService := announcement.NewServiceAnnouncement(announcementRepository, slugService, mediaService, webhookService, pushService)
+	announcementHandler := handler.NewHandlerAnnouncement(announcementService, fileStorage, mediaService, auditService)
+	announcement.StartStatusScheduler(jobs, announcementService, time.Minute)
+	webhook.StartDeliveryWorker(jobs, webhookService, 15*time.Second)
+
+	// item links point to the public site, SITE_URL falls back to the api host
+	feedHandler := handler.NewFeedHandler(announcementService, mediaService, siteURL, appURL)
+
+	// messages are composed for WhatsApp, sending only logs until a gateway is connected
+	broadcastService := broadcast.NewService(broadcastRepository, broadcast.NewLogBroadcaster(cfg.Broadcast.Target), appURL+"/s", siteURL, jakartaLocation())
+	broadcastHandler := handler.NewBroadcastHandler(broadcastService, announcementService, siteURL, auditService)
+
+	mailService := mailer.NewService(mailRepository, mailTransport(cfg.Mail), siteURL, jakartaLocation())
+	mailer.StartOutboxWorker(jobs, mailService, 30*time.Second)
+	mailer.StartDigestScheduler(jobs, mailService, time.Hour)
+
+	reviewService := review.NewService(reviewRepository, mailer.NewReviewNotifier(mailService), map[string]review.Reviewable{
+		review.ContentAnnouncement: announcementService,
+	})
+	reviewHandler := handler.NewReviewHandler(reviewService, auditService)
+
+	healthHandler := handler.NewHealthHandler(map[string]handler.ReadinessCheck{
+		"database": func(ctx context.Context) error {
+			return database.Ping(ctx, db)
+		},
+		"storage": fileStorage.Ping,
+	})
+
+	metrics.RegisterQueue("email_outbox", mailService.CountPending)
+	metrics.RegisterQueue("webhook_deliveries", webhookService.CountPending)
+	metrics.RegisterQueue("scheduled_announcements", announcementService.CountScheduled)
+
+	router.GET("/metrics", metrics.Handler(cfg.Metrics.Token))
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+	router.GET("/podcast.xml", recordingHandler.Podcast)
+	router.GET("/s/:code", broadcastHandler.ShortLink)
+	router.GET("/feeds/announcements.rss", feedHandler.AnnouncementsRSS)
+	router.GET("/feeds/announcements.atom", feedHandler.AnnouncementsAtom)
+	router.GET("/feeds/announcements.json", feedHandler.AnnouncementsJSON)
+	// article feeds wait for an article api, there is no article service or publish state to build them from yet
 
 	api := router.Group("/api/v1")
-	// for test api
-	api.GET("/test", userHandler.RegisterUser)
 	api.POST("/user/register", userHandler.RegisterUser)
 	api.POST("/user/login", userHandler.LoginUser)
 
@@ -75,10 +243,17 @@ func main() {
 	api.GET("/roles", authMiddleware(authService, userService), roleHandler.GetRoles)
 
 	api.POST("/announcement/add", authMiddleware(authService, userService), announcementHandler.AddAnnouncement)
-	api.GET("/announcements", announcementHandler.GetAllAnnouncement)
-	api.GET("/announcements/:id", announcementHandler.GetDetailAnnouncement)
+	api.GET("/announcements", optionalAuthMiddleware(authService, userService), announcementHandler.GetAllAnnouncement)
+	api.GET("/announcements/:id", optionalAuthMiddleware(authService, userService), announcementHandler.GetDetailAnnouncement)
+	api.GET("/announcements/slug/:slug", optionalAuthMiddleware(authService, userService), announcementHandler.GetDetailAnnouncementBySlug)
 	api.DELETE("/announcements/:id", authMiddleware(authService, userService), announcementHandler.DeleteAnnouncement)
 	api.PUT("/announcements/:id", authMiddleware(authService, userService), announcementHandler.UpdateAnnouncement)
+	api.POST("/announcements/:id/restore", authMiddleware(authService, userService), trashHandler.RestoreAnnouncement)
+	api.GET("/announcements/:id/revisions", authMiddleware(authService, userService), announcementHandler.GetRevisions)
+	api.GET("/announcements/:id/revisions/diff", authMiddleware(authService, userService), announcementHandler.DiffRevisions)
+	api.POST("/announcements/:id/revisions/:revision/rollback", authMiddleware(authService, userService), announcementHandler.RollbackRevision)
+	api.GET("/announcements/:id/share", optionalAuthMiddleware(authService, userService), broadcastHandler.ShareAnnouncement)
+	api.POST("/announcements/:id/share", authMiddleware(authService, userService), broadcastHandler.SendAnnouncement)
 
 	api.GET("/user/ustadz", authMiddleware(authService, userService), studyRundownHandler.GetListUstadzName)
 	api.POST("/rundown/add", authMiddleware(authService, userService), studyRundownHandler.AddStudy)
@@ -86,73 +261,153 @@ func main() {
 	api.GET("/rundown/:id", studyRundownHandler.GetDetailStudyRundown)
 	api.DELETE("/rundown/:id", authMiddleware(authService, userService), studyRundownHandler.DeleteStudyRundown)
 	api.PUT("/rundown/:id", authMiddleware(authService, userService), studyRundownHandler.UpdateStudyRundown)
+	api.POST("/rundown/:id/restore", authMiddleware(authService, userService), trashHandler.RestoreStudyRundown)
+	api.GET("/rundown/weekly/share", broadcastHandler.ShareWeeklyRundown)
+	api.POST("/rundown/weekly/share", authMiddleware(authService, userService), broadcastHandler.SendWeeklyRundown)
+
+	api.GET("/broadcast/templates", authMiddleware(authService, userService), broadcastHandler.GetTemplates)
+	api.PUT("/broadcast/templates/:name", authMiddleware(authService, userService), broadcastHandler.UpdateTemplate)
+	api.DELETE("/broadcast/templates/:name", authMiddleware(authService, userService), broadcastHandler.ResetTemplate)
+	api.POST("/broadcast/templates/:name/preview", authMiddleware(authService, userService), broadcastHandler.PreviewTemplate)
+
+	api.POST("/recordings", authMiddleware(authService, userService), recordingHandler.AddRecording)
+	api.GET("/recordings", recordingHandler.GetAllRecording)
+	api.GET("/recordings/:id", recordingHandler.GetDetailRecording)
+	api.DELETE("/recordings/:id", authMiddleware(authService, userService), recordingHandler.DeleteRecording)
+
+	api.POST("/articles/:id/restore", authMiddleware(authService, userService), trashHandler.RestoreArticle)
+	api.GET("/trash", authMiddleware(authService, userService), trashHandler.GetTrash)
+
+	api.GET("/audit", authMiddleware(authService, userService), auditHandler.GetAuditLogs)
 
-	//roleInsert := model.Role{
-	//	RoleName:  "super-admin",
-	//	CreatedAt: time.Time{},
-	//	UpdatedAt: time.Time{},
-	//}
-	//db.Save(&roleInsert)
+	api.POST("/reviews/submit", authMiddleware(authService, userService), reviewHandler.SubmitReview)
+	api.GET("/reviews/queue", authMiddleware(authService, userService), reviewHandler.GetQueue)
+	api.POST("/reviews/:id/approve", authMiddleware(authService, userService), reviewHandler.ApproveReview)
+	api.POST("/reviews/:id/reject", authMiddleware(authService, userService), reviewHandler.RejectReview)
 
-	router.Run()
+	api.POST("/uploads/presign", authMiddleware(authService, userService), uploadHandler.Presign)
+	api.POST("/uploads/complete", authMiddleware(authService, userService), uploadHandler.Complete)
+	api.PUT("/uploads/local", uploadHandler.PutLocal)
+	api.GET("/attachments/:entity_type/:entity_id", mediaHandler.GetAttachments)
+
+	api.POST("/webhooks", authMiddleware(authService, userService), webhookHandler.CreateEndpoint)
+	api.GET("/webhooks", authMiddleware(authService, userService), webhookHandler.GetEndpoints)
+	api.DELETE("/webhooks/:id", authMiddleware(authService, userService), webhookHandler.DeleteEndpoint)
+	api.GET("/webhooks/:id/deliveries", authMiddleware(authService, userService), webhookHandler.GetDeliveries)
+
+	api.POST("/push/devices", optionalAuthMiddleware(authService, userService), pushHandler.RegisterDevice)
+	api.DELETE("/push/devices", pushHandler.UnregisterDevice)
+	api.PUT("/push/topics", pushHandler.SetTopics)
+
+	api.GET("/media", authMiddleware(authService, userService), mediaHandler.GetListMedia)
+	api.GET("/media/:id", authMiddleware(authService, userService), mediaHandler.GetDetailMedia)
+	api.POST("/media/:id/attach", authMiddleware(authService, userService), mediaHandler.AttachMedia)
+
+	runServer(router, cfg.App.Port, cfg.Server, jobs, db, flushTracing)
 }
 
 func authMiddleware(autService auth.Service, userService user.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if !strings.Contains(authHeader, "Bearer") {
-			response := helper.ApiResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+		currentUser, err := userFromToken(c.Request.Context(), c.GetHeader("Authorization"), autService, userService)
+		if err != nil {
+			c.Error(apperr.Unauthorized(apperr.CodeUnauthorized, "Unauthorized"))
+			c.Abort()
 			return
 		}
 
-		tokenString := ""
-		arrayToken := strings.Split(authHeader, " ")
+		c.Set("currentUser", currentUser)
+	}
 
-		if len(arrayToken) == 2 {
-			tokenString = arrayToken[1]
-		}
+}
 
-		token, err := autService.ValidateToken(tokenString)
-		if err != nil {
-			response := helper.ApiResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
+// optionalAuthMiddleware sets currentUser when a valid token is sent but lets anonymous visitors through
+func optionalAuthMiddleware(autService auth.Service, userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := userFromToken(c.Request.Context(), c.GetHeader("Authorization"), autService, userService)
+		if err == nil {
+			c.Set("currentUser", currentUser)
 		}
+	}
+}
 
-		claim, ok := token.Claims.(jwt.MapClaims)
+func userFromToken(ctx context.Context, authHeader string, autService auth.Service, userService user.UserService) (model.User, error) {
+	if !strings.Contains(authHeader, "Bearer") {
+		return model.User{}, errors.New("missing bearer token")
+	}
 
-		if !ok || !token.Valid {
-			response := helper.ApiResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
-		}
+	tokenString := ""
+	arrayToken := strings.Split(authHeader, " ")
 
-		userId := uint(claim["user_id"].(float64))
+	if len(arrayToken) == 2 {
+		tokenString = arrayToken[1]
+	}
 
-		currentUser, errFindUser := userService.GetUserByID(userId)
+	token, err := autService.ValidateToken(tokenString)
+	if err != nil {
+		return model.User{}, err
+	}
 
-		if errFindUser != nil {
-			response := helper.ApiResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
-		}
+	claim, ok := token.Claims.(jwt.MapClaims)
 
-		c.Set("currentUser", currentUser)
+	if !ok || !token.Valid {
+		return model.User{}, errors.New("invalid token")
 	}
 
+	userId, ok := claim["user_id"].(float64)
+	if !ok {
+		return model.User{}, errors.New("invalid token")
+	}
+
+	return userService.GetUserByID(ctx, uint(userId))
 }
 
-func configS3() *s3.Client {
+// pushProviders uses the real push networks when their credentials are configured. The fake one only stands in
+// for a missing network in development, in production those devices are counted as failures
+func pushProviders(cfg config.PushConfig, env string) map[string]push.Provider {
+	providers := map[string]push.Provider{}
+	if env == config.EnvDevelopment {
+		fake := push.NewFakeProvider()
+		providers[push.ProviderFCM] = fake
+		providers[push.ProviderAPNS] = fake
+	}
 
-	creds := credentials.NewStaticCredentialsProvider(os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), "")
+	if cfg.FCMCredentialsFile != "" {
+		fcm, err := push.NewFCMProvider(cfg.FCMCredentialsFile)
+		if err != nil {
+			log.Fatalf("fcm: %v", err)
+		}
+		providers[push.ProviderFCM] = fcm
+	}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithCredentialsProvider(creds), config.WithRegion(os.Getenv("AWS_REGION")))
+	if cfg.APNS.KeyFile != "" {
+		apns, err := push.NewAPNSProvider(cfg.APNS.KeyFile, cfg.APNS.KeyID, cfg.APNS.TeamID, cfg.APNS.Topic, cfg.APNS.Production)
+		if err != nil {
+			log.Fatalf("apns: %v", err)
+		}
+		providers[push.ProviderAPNS] = apns
+	}
 
-	if err != nil {
-		log.Fatal(err)
+	for _, name := range []string{push.ProviderFCM, push.ProviderAPNS} {
+		if providers[name] == nil {
+			log.Printf("push: %s is not configured, its devices get no notifications", name)
+		}
 	}
+	return providers
+}
 
-	return s3.NewFromConfig(cfg)
+// mailTransport sends through SMTP when MAIL_DRIVER=smtp, otherwise emails are written to MAIL_DIR
+func mailTransport(cfg config.MailConfig) mailer.Mailer {
+	if cfg.Driver == "smtp" {
+		return mailer.NewSMTPMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.From)
+	}
+	return mailer.NewFileMailer(cfg.Dir, cfg.From)
+}
 
+// jakartaLocation is the time zone the rundown schedule is written in
+func jakartaLocation() *time.Location {
+	location, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return location
 }
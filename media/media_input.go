@@ -0,0 +1,33 @@
+package media
+
+type AssetInput struct {
+	Key         string
+	URL         string
+	FileName    string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	UserID      uint
+	ParentID    *uint
+	Variant     string
+}
+
+type MediaListInput struct {
+	Type   string `form:"type" binding:"omitempty,oneof=image audio application"`
+	UserID uint   `form:"user_id"`
+}
+
+type AttachmentListInput struct {
+	EntityType string `uri:"entity_type" binding:"required,oneof=announcement rundown article video recording"`
+	EntityID   uint   `uri:"entity_id" binding:"required"`
+}
+
+type MediaDetailInput struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type AttachInput struct {
+	EntityType string `json:"entity_type" binding:"required,oneof=announcement rundown article video recording"`
+	EntityID   uint   `json:"entity_id" binding:"required"`
+}
@@ -0,0 +1,53 @@
+package media
+
+import (
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type MediaFormatter struct {
+	ID          uint              `json:"id"`
+	Key         string            `json:"key"`
+	URL         string            `json:"url"`
+	FileName    string            `json:"file_name"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Variants    map[string]string `json:"variants"`
+	References  int               `json:"references"`
+	UploadedBy  string            `json:"uploaded_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+func MediaJsonFormatter(asset model.MediaAsset) MediaFormatter {
+	variants := map[string]string{}
+	for _, variant := range asset.Variants {
+		variants[variant.Variant] = variant.URL
+	}
+
+	return MediaFormatter{
+		ID:          asset.ID,
+		Key:         asset.Key,
+		URL:         asset.URL,
+		FileName:    asset.FileName,
+		ContentType: asset.ContentType,
+		Size:        asset.Size,
+		Width:       asset.Width,
+		Height:      asset.Height,
+		Variants:    variants,
+		References:  len(asset.References),
+		UploadedBy:  asset.User.Name,
+		CreatedAt:   asset.CreatedAt,
+	}
+}
+
+func ListMediaJsonFormatter(assets []model.MediaAsset) []MediaFormatter {
+	formatter := []MediaFormatter{}
+
+	for _, asset := range assets {
+		formatter = append(formatter, MediaJsonFormatter(asset))
+	}
+
+	return formatter
+}
@@ -0,0 +1,194 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type MediaRepository interface {
+	SaveAsset(ctx context.Context, asset model.MediaAsset) (model.MediaAsset, error)
+	FindByKey(ctx context.Context, key string) (model.MediaAsset, error)
+	FindByID(ctx context.Context, ID uint) (model.MediaAsset, error)
+	FindByURLs(ctx context.Context, urls []string) ([]model.MediaAsset, error)
+	GetListAsset(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.MediaAsset, int, error)
+	GetEntityAssets(ctx context.Context, entityType string, entityID uint, field string) ([]model.MediaAsset, error)
+	ReplaceReferences(ctx context.Context, entityType string, entityID uint, field string, assetIDs []uint) error
+	AddReference(ctx context.Context, reference model.MediaReference) error
+	GetUnreferenced(ctx context.Context, before time.Time) ([]model.MediaAsset, error)
+	DeleteAsset(ctx context.Context, ID uint) error
+	GetExpiredUploads(ctx context.Context, before time.Time) ([]model.Upload, error)
+	DeleteUpload(ctx context.Context, ID uint) error
+	EntityExists(ctx context.Context, entityType string, entityID uint) (bool, error)
+}
+
+type mediaRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *mediaRepository {
+	return &mediaRepository{db}
+}
+
+func (r *mediaRepository) SaveAsset(ctx context.Context, asset model.MediaAsset) (model.MediaAsset, error) {
+	err := r.db.WithContext(ctx).Omit(clause.Associations).Save(&asset).Error
+	if err != nil {
+		return asset, err
+	}
+	return asset, nil
+}
+
+func (r *mediaRepository) FindByKey(ctx context.Context, key string) (model.MediaAsset, error) {
+	var asset model.MediaAsset
+	err := r.db.WithContext(ctx).Where("object_key = ?", key).Find(&asset).Error
+	if err != nil {
+		return asset, err
+	}
+	return asset, nil
+}
+
+func (r *mediaRepository) FindByID(ctx context.Context, ID uint) (model.MediaAsset, error) {
+	var asset model.MediaAsset
+	err := r.db.WithContext(ctx).Preload("User").Preload("Variants").Preload("References").Where("id = ?", ID).First(&asset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return asset, errNotFound
+	}
+	if err != nil {
+		return asset, err
+	}
+	return asset, nil
+}
+
+func (r *mediaRepository) FindByURLs(ctx context.Context, urls []string) ([]model.MediaAsset, error) {
+	var assets []model.MediaAsset
+	err := r.db.WithContext(ctx).Where("url IN ?", urls).Find(&assets).Error
+	if err != nil {
+		return assets, err
+	}
+	return assets, nil
+}
+
+func (r *mediaRepository) GetListAsset(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.MediaAsset, int, error) {
+	var assets []model.MediaAsset
+	err := r.db.WithContext(ctx).Preload("User").Preload("Variants").Preload("References").
+		Where("parent_id IS NULL").
+		Scopes(filter, list).
+		Order("created_at desc").
+		Find(&assets).Error
+	if err != nil {
+		return assets, 0, err
+	}
+
+	totalCount := int64(0)
+	r.db.WithContext(ctx).Model(&model.MediaAsset{}).Where("parent_id IS NULL").Scopes(filter).Count(&totalCount)
+	return assets, int(totalCount), nil
+}
+
+func (r *mediaRepository) GetEntityAssets(ctx context.Context, entityType string, entityID uint, field string) ([]model.MediaAsset, error) {
+	var assets []model.MediaAsset
+	err := r.db.WithContext(ctx).Preload("User").Preload("Variants").
+		Joins("JOIN media_references ON media_references.media_asset_id = media_assets.id").
+		Where("media_references.entity_type = ? AND media_references.entity_id = ? AND media_references.field = ?", entityType, entityID, field).
+		Order("media_references.created_at asc").
+		Find(&assets).Error
+	if err != nil {
+		return assets, err
+	}
+	return assets, nil
+}
+
+func (r *mediaRepository) ReplaceReferences(ctx context.Context, entityType string, entityID uint, field string, assetIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("entity_type = ? AND entity_id = ? AND field = ?", entityType, entityID, field).Delete(&model.MediaReference{}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, assetID := range assetIDs {
+			reference := model.MediaReference{MediaAssetID: assetID, EntityType: entityType, EntityID: entityID, Field: field}
+			err = tx.Create(&reference).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *mediaRepository) AddReference(ctx context.Context, reference model.MediaReference) error {
+	err := r.db.WithContext(ctx).Create(&reference).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetUnreferenced returns parent assets where neither the asset nor any of its variants is referenced
+func (r *mediaRepository) GetUnreferenced(ctx context.Context, before time.Time) ([]model.MediaAsset, error) {
+	var assets []model.MediaAsset
+	err := r.db.WithContext(ctx).Preload("Variants").
+		Where("parent_id IS NULL AND created_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM media_references WHERE media_references.media_asset_id = media_assets.id)").
+		Where("NOT EXISTS (SELECT 1 FROM media_references JOIN media_assets variants ON variants.id = media_references.media_asset_id WHERE variants.parent_id = media_assets.id)").
+		Find(&assets).Error
+	if err != nil {
+		return assets, err
+	}
+	return assets, nil
+}
+
+func (r *mediaRepository) DeleteAsset(ctx context.Context, ID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("parent_id = ?", ID).Delete(&model.MediaAsset{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.MediaAsset{}, ID).Error
+	})
+}
+
+func (r *mediaRepository) GetExpiredUploads(ctx context.Context, before time.Time) ([]model.Upload, error) {
+	var uploads []model.Upload
+	err := r.db.WithContext(ctx).Where("status = ? AND expires_at < ?", "pending", before).Find(&uploads).Error
+	if err != nil {
+		return uploads, err
+	}
+	return uploads, nil
+}
+
+func (r *mediaRepository) DeleteUpload(ctx context.Context, ID uint) error {
+	err := r.db.WithContext(ctx).Delete(&model.Upload{}, ID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *mediaRepository) EntityExists(ctx context.Context, entityType string, entityID uint) (bool, error) {
+	var entity interface{}
+	switch entityType {
+	case EntityAnnouncement:
+		entity = &model.Announcement{}
+	case EntityRundown:
+		entity = &model.StudyRundown{}
+	case EntityArticle:
+		entity = &model.Article{}
+	case EntityVideo:
+		entity = &model.StudyVideo{}
+	case EntityRecording:
+		entity = &model.AudioRecording{}
+	default:
+		return false, apperr.Validation("unknown_entity_type", "unknown entity type")
+	}
+
+	count := int64(0)
+	err := r.db.WithContext(ctx).Model(entity).Where("id = ?", entityID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,258 @@
+package media
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/storage"
+	"nurul-iman-blok-m/worker"
+	"strings"
+	"time"
+)
+
+const (
+	FieldBanner     = "banner"
+	FieldAttachment = "attachment"
+	FieldAudio      = "audio"
+
+	EntityAnnouncement         = "announcement"
+	EntityAnnouncementRevision = "announcement_revision"
+	EntityRundown              = "rundown"
+	EntityArticle              = "article"
+	EntityVideo                = "video"
+	EntityRecording            = "recording"
+
+	// GracePeriod keeps fresh assets out of the garbage collector while the request using them is still running
+	GracePeriod = 24 * time.Hour
+)
+
+var (
+	errNotFound       = apperr.NotFound("media_not_found", "media not found")
+	errEntityNotFound = apperr.NotFound("entity_not_found", "entity not found")
+)
+
+type MediaService interface {
+	RegisterAsset(ctx context.Context, input AssetInput) (model.MediaAsset, error)
+	GetAsset(ctx context.Context, input MediaDetailInput) (model.MediaAsset, error)
+	GetListAsset(ctx context.Context, input MediaListInput, list func(db *gorm.DB) *gorm.DB) ([]model.MediaAsset, int, error)
+	Attach(ctx context.Context, input MediaDetailInput, attach AttachInput) error
+	GetAttachments(ctx context.Context, input AttachmentListInput) ([]model.MediaAsset, error)
+	GetAssetsByURL(ctx context.Context, urls ...string) ([]model.MediaAsset, error)
+	SyncReferences(ctx context.Context, entityType string, entityID uint, field string, urls ...string) error
+	CollectGarbage(ctx context.Context) (int, error)
+}
+
+type mediaService struct {
+	repository MediaRepository
+	storage    storage.Storage
+}
+
+func NewService(repository MediaRepository, storage storage.Storage) *mediaService {
+	return &mediaService{repository, storage}
+}
+
+// RegisterAsset returns the existing asset when the key is already known, content keys make uploads dedupe here too
+func (s *mediaService) RegisterAsset(ctx context.Context, input AssetInput) (model.MediaAsset, error) {
+	existing, err := s.repository.FindByKey(ctx, input.Key)
+	if err != nil {
+		return existing, err
+	}
+	if existing.ID != 0 {
+		return existing, nil
+	}
+
+	asset := model.MediaAsset{
+		ParentID:    input.ParentID,
+		Variant:     input.Variant,
+		Key:         input.Key,
+		URL:         input.URL,
+		FileName:    input.FileName,
+		ContentType: input.ContentType,
+		Size:        input.Size,
+		Width:       input.Width,
+		Height:      input.Height,
+		UserID:      input.UserID,
+	}
+
+	saved, err := s.repository.SaveAsset(ctx, asset)
+	if err != nil {
+		return saved, err
+	}
+	return saved, nil
+}
+
+func (s *mediaService) GetAsset(ctx context.Context, input MediaDetailInput) (model.MediaAsset, error) {
+	asset, err := s.repository.FindByID(ctx, input.ID)
+	if err != nil {
+		return asset, err
+	}
+	return asset, nil
+}
+
+func (s *mediaService) GetListAsset(ctx context.Context, input MediaListInput, list func(db *gorm.DB) *gorm.DB) ([]model.MediaAsset, int, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		if input.Type != "" {
+			db = db.Where("content_type LIKE ?", input.Type+"/%")
+		}
+		if input.UserID != 0 {
+			db = db.Where("user_id = ?", input.UserID)
+		}
+		return db
+	}
+
+	assets, total, err := s.repository.GetListAsset(ctx, filter, list)
+	if err != nil {
+		return assets, total, err
+	}
+	return assets, total, nil
+}
+
+// Attach reuses a library asset on another entity without uploading it again
+func (s *mediaService) Attach(ctx context.Context, input MediaDetailInput, attach AttachInput) error {
+	asset, err := s.repository.FindByID(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.repository.EntityExists(ctx, attach.EntityType, attach.EntityID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errEntityNotFound
+	}
+
+	for _, reference := range asset.References {
+		if reference.EntityType == attach.EntityType && reference.EntityID == attach.EntityID && reference.Field == FieldAttachment {
+			return nil
+		}
+	}
+
+	return s.repository.AddReference(ctx, model.MediaReference{
+		MediaAssetID: asset.ID,
+		EntityType:   attach.EntityType,
+		EntityID:     attach.EntityID,
+		Field:        FieldAttachment,
+	})
+}
+
+func (s *mediaService) GetAttachments(ctx context.Context, input AttachmentListInput) ([]model.MediaAsset, error) {
+	assets, err := s.repository.GetEntityAssets(ctx, input.EntityType, input.EntityID, FieldAttachment)
+	if err != nil {
+		return assets, err
+	}
+	return assets, nil
+}
+
+func (s *mediaService) GetAssetsByURL(ctx context.Context, urls ...string) ([]model.MediaAsset, error) {
+	if len(urls) == 0 {
+		return []model.MediaAsset{}, nil
+	}
+
+	assets, err := s.repository.FindByURLs(ctx, urls)
+	if err != nil {
+		return assets, err
+	}
+	return assets, nil
+}
+
+// SyncReferences makes the given urls the only assets referenced by the field of an entity,
+// urls that are not in the library (e.g. banners uploaded before it existed) are skipped
+func (s *mediaService) SyncReferences(ctx context.Context, entityType string, entityID uint, field string, urls ...string) error {
+	var wanted []string
+	for _, url := range urls {
+		if url != "" {
+			wanted = append(wanted, url)
+		}
+	}
+
+	var assetIDs []uint
+	if len(wanted) > 0 {
+		assets, err := s.repository.FindByURLs(ctx, wanted)
+		if err != nil {
+			return err
+		}
+		for _, asset := range assets {
+			assetIDs = append(assetIDs, asset.ID)
+		}
+	}
+
+	return s.repository.ReplaceReferences(ctx, entityType, entityID, field, assetIDs)
+}
+
+// CollectGarbage deletes assets nothing points to anymore and presigned uploads that were never completed
+func (s *mediaService) CollectGarbage(ctx context.Context) (int, error) {
+	before := time.Now().Add(-GracePeriod)
+	collected := 0
+
+	assets, err := s.repository.GetUnreferenced(ctx, before)
+	if err != nil {
+		return collected, err
+	}
+	for _, asset := range assets {
+		keys := []string{asset.Key}
+		for _, variant := range asset.Variants {
+			keys = append(keys, variant.Key)
+		}
+		for _, key := range keys {
+			errDelete := s.storage.Delete(ctx, key)
+			if errDelete != nil {
+				return collected, errDelete
+			}
+		}
+
+		errDelete := s.repository.DeleteAsset(ctx, asset.ID)
+		if errDelete != nil {
+			return collected, errDelete
+		}
+		collected++
+	}
+
+	uploads, err := s.repository.GetExpiredUploads(ctx, before)
+	if err != nil {
+		return collected, err
+	}
+	for _, upload := range uploads {
+		errDelete := s.storage.Delete(ctx, upload.Key)
+		if errDelete != nil {
+			return collected, errDelete
+		}
+
+		errDelete = s.repository.DeleteUpload(ctx, upload.ID)
+		if errDelete != nil {
+			return collected, errDelete
+		}
+		collected++
+	}
+
+	return collected, nil
+}
+
+// VariantURL picks the url of a resized variant, falling back to the asset itself
+func VariantURL(asset model.MediaAsset, variant string) string {
+	for _, item := range asset.Variants {
+		if item.Variant == variant {
+			return item.URL
+		}
+	}
+	return asset.URL
+}
+
+func IsImage(asset model.MediaAsset) bool {
+	return strings.HasPrefix(asset.ContentType, "image/")
+}
+
+func StartGarbageCollector(jobs *worker.Group, service MediaService, interval time.Duration) {
+	jobs.Every(interval, false, func(ctx context.Context) {
+		collected, err := service.CollectGarbage(ctx)
+		if err != nil {
+			log.Printf("media garbage collection failed: %v", err)
+			return
+		}
+		if collected > 0 {
+			log.Printf("media garbage collection removed %d orphaned files", collected)
+		}
+	})
+}
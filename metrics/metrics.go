@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	UploadBanner    = "banner"
+	UploadPresigned = "presigned"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to answer HTTP requests by route and status.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route", "status"})
+
+	dbDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time spent in database statements by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+	}, []string{"operation", "table"})
+
+	dbErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Database statements that failed, not found rows excluded.",
+	}, []string{"operation", "table"})
+
+	uploadSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "upload_size_bytes",
+		Help:    "Size of accepted uploads.",
+		Buckets: prometheus.ExponentialBuckets(64*1024, 2, 12),
+	}, []string{"kind"})
+
+	uploadFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "upload_failures_total",
+		Help: "Rejected or failed uploads by reason.",
+	}, []string{"kind", "reason"})
+)
+
+// ObserveUpload records an accepted upload of kind UploadBanner or UploadPresigned
+func ObserveUpload(kind string, size int64) {
+	uploadSize.WithLabelValues(kind).Observe(float64(size))
+}
+
+// UploadFailed counts a failed upload, keep reason to a short fixed word such as too_large
+func UploadFailed(kind string, reason string) {
+	uploadFailures.WithLabelValues(kind, reason).Inc()
+}
+
+// RegisterQueue exposes the backlog of a background job as background_queue_pending{queue="name"}.
+// count runs on every scrape with a short timeout, a failing count is reported as NaN
+func RegisterQueue(name string, count func(ctx context.Context) (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "background_queue_pending",
+		Help:        "Items waiting for a background job.",
+		ConstLabels: prometheus.Labels{"queue": name},
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		pending, err := count(ctx)
+		if err != nil {
+			log.Printf("metrics: counting %s queue: %v", name, err)
+			return math.NaN()
+		}
+		return float64(pending)
+	})
+}
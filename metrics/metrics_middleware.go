@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware records every request under its route pattern, e.g. /api/v1/announcements/:id,
+// so the number of label values stays bounded whatever ids clients ask for
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the metrics, when token is set scrapers have to send it as bearer token
+func Handler(token string) gin.HandlerFunc {
+	metrics := promhttp.Handler()
+	return func(c *gin.Context) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		metrics.ServeHTTP(c.Writer, c.Request)
+	}
+}
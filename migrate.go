@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"nurul-iman-blok-m/config"
+	"nurul-iman-blok-m/database"
+	"nurul-iman-blok-m/migration"
+	"os"
+	"time"
+)
+
+const migrateUsage = `usage: nurul-iman-blok-m migrate <command>
+
+commands:
+  up [-steps n]               apply pending migrations, all of them by default
+  down [-steps n]             roll back the latest migrations, one by default
+  status                      list migrations and when they were applied
+  create [-kind sql|go] name  write an empty migration into ./migration`
+
+// runMigrate is the migrate subcommand
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	command := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	steps := command.Int("steps", 0, "number of migrations")
+	kind := command.String("kind", "sql", "sql or go, for create")
+	command.Parse(args[1:])
+
+	if args[0] == "create" {
+		if command.NArg() != 1 {
+			log.Fatal("migrate create needs a name, e.g. migrate create add_event_location")
+		}
+		files, err := migration.Create("migration", command.Arg(0), *kind, time.Now())
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, file := range files {
+			fmt.Println("created", file)
+		}
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator, err := migration.NewMigrator(database.Db(cfg.Database))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "up":
+		done, errUp := migrator.Up(*steps)
+		printMigrations("applied", done)
+		if errUp != nil {
+			log.Fatal(errUp)
+		}
+	case "down":
+		if *steps == 0 {
+			*steps = 1
+		}
+		done, errDown := migrator.Down(*steps)
+		printMigrations("rolled back", done)
+		if errDown != nil {
+			log.Fatal(errDown)
+		}
+	case "status":
+		statuses, errStatus := migrator.Status()
+		if errStatus != nil {
+			log.Fatal(errStatus)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-20s %s_%s\n", appliedAt, status.Migration.Version, status.Migration.Name)
+		}
+	default:
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+}
+
+func printMigrations(action string, migrations []migration.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("nothing to do")
+	}
+	for _, item := range migrations {
+		fmt.Printf("%s %s_%s\n", action, item.Version, item.Name)
+	}
+}
+
+// checkMigrations stops production from serving a schema older than the code, development
+// catches up on its own
+func checkMigrations(db *gorm.DB, env string, allowPending bool) {
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if env == config.EnvDevelopment {
+		done, errUp := migrator.Up(0)
+		printMigrations("applied", done)
+		if errUp != nil {
+			log.Fatal(errUp)
+		}
+		return
+	}
+
+	errCheck := migrator.Check()
+	if errors.Is(errCheck, migration.ErrPending) && allowPending {
+		log.Printf("warning: %v", errCheck)
+		return
+	}
+	if errCheck != nil {
+		log.Fatal(errCheck)
+	}
+}
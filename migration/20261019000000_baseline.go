@@ -0,0 +1,22 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/migration/baseline"
+)
+
+// the baseline creates the schema the api had before versioned migrations. On a database that was
+// kept up to date by AutoMigrate it finds every table in place and only records itself as applied
+func init() {
+	Register(Migration{
+		Version: "20261019000000",
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(baseline.Tables...)
+		},
+		// DropTable orders the tables by their foreign keys, drivers without DROP ... CASCADE need that
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(baseline.Tables...)
+		},
+	})
+}
@@ -0,0 +1,61 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// pushDispatchOutbox holds the columns this migration adds to push_dispatches, frozen here so later
+// model changes do not alter what it does. Rows from before it were sent already
+type pushDispatchOutbox struct {
+	Data          string    `gorm:"type:text"`
+	Status        string    `gorm:"size:20;index;not null;default:sent"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string    `gorm:"type:text"`
+	SentAt        *time.Time
+}
+
+func (pushDispatchOutbox) TableName() string {
+	return "push_dispatches"
+}
+
+var pushDispatchOutboxColumns = []string{"Data", "Status", "Attempts", "NextAttemptAt", "LastError", "SentAt"}
+
+// push dispatches become an outbox, the worker sends them and retries the ones that failed
+func init() {
+	Register(Migration{
+		Version: "20261019060000",
+		Name:    "push_dispatch_outbox",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range pushDispatchOutboxColumns {
+				err := tx.Migrator().AddColumn(&pushDispatchOutbox{}, column)
+				if err != nil {
+					return err
+				}
+			}
+			for _, column := range []string{"Status", "NextAttemptAt"} {
+				err := tx.Migrator().CreateIndex(&pushDispatchOutbox{}, column)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"Status", "NextAttemptAt"} {
+				err := tx.Migrator().DropIndex(&pushDispatchOutbox{}, column)
+				if err != nil {
+					return err
+				}
+			}
+			for _, column := range pushDispatchOutboxColumns {
+				err := tx.Migrator().DropColumn(&pushDispatchOutbox{}, column)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,125 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+// the slug tables as this migration indexes them, frozen like the other migrations
+type uniqueSlugAnnouncement struct {
+	Slug string `gorm:"size:255;not null;uniqueIndex"`
+}
+
+func (uniqueSlugAnnouncement) TableName() string {
+	return "announcements"
+}
+
+type uniqueSlugArticle struct {
+	Slug string `gorm:"size:255;not null;uniqueIndex"`
+}
+
+func (uniqueSlugArticle) TableName() string {
+	return "articles"
+}
+
+// study videos had an unsized slug, MySQL can not index a TEXT column without a prefix length
+type uniqueSlugStudyVideo struct {
+	Slug string `gorm:"size:255;uniqueIndex"`
+}
+
+func (uniqueSlugStudyVideo) TableName() string {
+	return "study_videos"
+}
+
+type studyVideoTextSlug struct {
+	Slug string
+}
+
+func (studyVideoTextSlug) TableName() string {
+	return "study_videos"
+}
+
+var uniqueSlugTables = []interface {
+	TableName() string
+}{&uniqueSlugAnnouncement{}, &uniqueSlugArticle{}, &uniqueSlugStudyVideo{}}
+
+// slugs get a unique index so two concurrent creates can not end up with the same slug.
+// Rows that already share one keep it on the oldest row, the others get the next free suffix
+func init() {
+	Register(Migration{
+		Version: "20261019070000",
+		Name:    "unique_slugs",
+		Up: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() == "mysql" {
+				err := tx.Migrator().AlterColumn(&uniqueSlugStudyVideo{}, "Slug")
+				if err != nil {
+					return err
+				}
+			}
+			for _, table := range uniqueSlugTables {
+				err := renameDuplicateSlugs(tx, table.TableName())
+				if err != nil {
+					return err
+				}
+				err = tx.Migrator().CreateIndex(table, "Slug")
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range uniqueSlugTables {
+				err := tx.Migrator().DropIndex(table, "Slug")
+				if err != nil {
+					return err
+				}
+			}
+			if tx.Dialector.Name() == "mysql" {
+				return tx.Migrator().AlterColumn(&studyVideoTextSlug{}, "Slug")
+			}
+			return nil
+		},
+	})
+}
+
+func renameDuplicateSlugs(tx *gorm.DB, table string) error {
+	var duplicates []string
+	err := tx.Table(table).Where("slug IS NOT NULL").Group("slug").Having("COUNT(*) > 1").Pluck("slug", &duplicates).Error
+	if err != nil {
+		return err
+	}
+
+	for _, duplicate := range duplicates {
+		var ids []uint
+		err = tx.Table(table).Where("slug = ?", duplicate).Order("id").Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+
+		base := duplicate
+		if base == "" {
+			base = strings.TrimSuffix(table, "s")
+		}
+		suffix := 2
+		for _, id := range ids[1:] {
+			for ; ; suffix++ {
+				var taken int64
+				err = tx.Table(table).Where("slug = ?", base+"-"+strconv.Itoa(suffix)).Count(&taken).Error
+				if err != nil {
+					return err
+				}
+				if taken == 0 {
+					break
+				}
+			}
+			err = tx.Table(table).Where("id = ?", id).Update("slug", base+"-"+strconv.Itoa(suffix)).Error
+			if err != nil {
+				return err
+			}
+			suffix++
+		}
+	}
+	return nil
+}
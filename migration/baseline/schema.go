@@ -0,0 +1,297 @@
+// Package baseline is a frozen copy of the models as they were when versioned migrations were
+// introduced. The baseline migration creates its tables from these types, so later changes to
+// package model never change what the baseline does. Do not edit this file.
+package baseline
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type User struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement"`
+	Name     string `gorm:"type:varchar(100);NOT NULL"`
+	Email    string `gorm:"type:varchar(100);NOT NULL"`
+	Password string `gorm:"type:varchar(255);NOT NULL"`
+	// for migration
+	Role          Role
+	RoleID        uint `gorm:"index;NOT NULL"`
+	Announcements []Announcement
+	Articles      []Article
+	StudyRundown  []StudyRundown
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Role struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	RoleName  string `gorm:"type:varchar(100);NOT NULL"`
+	Users     []User
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Announcement struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement"`
+	Title          string `gorm:"size:255;not null"`
+	Description    string `gorm:"type:text;not null"`
+	Images         string `gorm:"size:255;not null"`
+	ImageMedium    string `gorm:"size:255"`
+	ImageThumbnail string `gorm:"size:255"`
+	User           User
+	UserID         uint       `gorm:"index;not null"`
+	Slug           string     `gorm:"size:255;not null"`
+	Status         string     `gorm:"size:20;not null;default:published;index"`
+	PublishAt      *time.Time `gorm:"index"`
+	ExpireAt       *time.Time `gorm:"index"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+type Article struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement;not null"`
+	Title       string `gorm:"size:100;not null"`
+	Description string `gorm:"type:text;not null"`
+	User        User
+	UserID      uint `gorm:"index;not null"`
+	Category    Category
+	CategoryID  uint   `gorm:"index;not null"`
+	Slug        string `gorm:"size:255;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+type Category struct {
+	ID           uint   `gorm:"autoIncrement;not null;primaryKey"`
+	CategoryName string `gorm:"size(100);not null"`
+	Articles     []Article
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type StudyRundown struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement;not null"`
+	Title        string `gorm:"size:100;not null"`
+	OnScheduled  bool   `gorm:"type:boolean"`
+	ScheduleDate string `gorm:"size:100; not null"`
+	User         User
+	UserID       uint   `gorm:"index;not null"`
+	Time         string `gorm:"size:100;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+type StudyVideo struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement;not null"`
+	Title     string `gorm:"size:100;not null"`
+	thumbnail string `gorm:"size:100;not null"`
+	Url       string `gorm:"size:255;not null"`
+	Slug      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	ActorID    uint      `gorm:"index"`
+	ActorName  string    `gorm:"size:100"`
+	Action     string    `gorm:"size:20;index;not null"`
+	EntityType string    `gorm:"size:50;index;not null"`
+	EntityID   uint      `gorm:"index"`
+	Changes    string    `gorm:"type:text"`
+	IPAddress  string    `gorm:"size:45"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+type AnnouncementRevision struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement"`
+	AnnouncementID uint   `gorm:"index;not null"`
+	Revision       uint   `gorm:"not null"`
+	Title          string `gorm:"size:255;not null"`
+	Description    string `gorm:"type:text;not null"`
+	Images         string `gorm:"size:255;not null"`
+	ImageMedium    string `gorm:"size:255"`
+	ImageThumbnail string `gorm:"size:255"`
+	Slug           string `gorm:"size:255;not null"`
+	User           User
+	UserID         uint `gorm:"index"`
+	CreatedAt      time.Time
+}
+
+type Review struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	ContentType string `gorm:"size:50;index;not null"`
+	ContentID   uint   `gorm:"index;not null"`
+	Title       string `gorm:"size:255;not null"`
+	Author      User   `gorm:"foreignKey:AuthorID"`
+	AuthorID    uint   `gorm:"index;not null"`
+	Reviewer    User   `gorm:"foreignKey:ReviewerID"`
+	ReviewerID  *uint  `gorm:"index"`
+	Status      string `gorm:"size:20;index;not null"`
+	Comment     string `gorm:"type:text"`
+	DecidedAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Upload struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Key         string `gorm:"column:object_key;size:255;uniqueIndex;not null"`
+	URL         string `gorm:"size:255"`
+	FileName    string `gorm:"size:255"`
+	ContentType string `gorm:"size:100;not null"`
+	Size        int64
+	Status      string `gorm:"size:20;index;not null"`
+	EntityType  string `gorm:"size:50;index"`
+	EntityID    uint   `gorm:"index"`
+	User        User
+	UserID      uint `gorm:"index;not null"`
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type MediaAsset struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	ParentID    *uint  `gorm:"index"`
+	Variant     string `gorm:"size:20"`
+	Key         string `gorm:"column:object_key;size:255;uniqueIndex;not null"`
+	URL         string `gorm:"size:255;index;not null"`
+	FileName    string `gorm:"size:255"`
+	ContentType string `gorm:"size:100;not null"`
+	Size        int64
+	Width       int
+	Height      int
+	User        User
+	UserID      uint             `gorm:"index"`
+	Variants    []MediaAsset     `gorm:"foreignKey:ParentID"`
+	References  []MediaReference `gorm:"foreignKey:MediaAssetID"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type MediaReference struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	MediaAssetID uint   `gorm:"index;not null"`
+	EntityType   string `gorm:"size:50;index:idx_media_reference_entity;not null"`
+	EntityID     uint   `gorm:"index:idx_media_reference_entity;not null"`
+	Field        string `gorm:"size:50;not null"`
+	CreatedAt    time.Time
+}
+
+type AudioRecording struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement;not null"`
+	Title          string `gorm:"size:100;not null"`
+	Description    string `gorm:"type:text"`
+	StudyRundown   StudyRundown
+	StudyRundownID uint `gorm:"index;not null"`
+	Speaker        User
+	SpeakerID      uint   `gorm:"index;not null"`
+	URL            string `gorm:"size:255;not null"`
+	ContentType    string `gorm:"size:100;not null"`
+	Size           int64
+	Duration       int // seconds
+	RecordedAt     time.Time
+	User           User
+	UserID         uint `gorm:"index;not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type WebhookEndpoint struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	URL       string `gorm:"size:255;not null"`
+	Secret    string `gorm:"size:100;not null"`
+	Events    string `gorm:"size:500;not null"` // comma separated event names
+	Active    bool   `gorm:"not null;default:true"`
+	User      User
+	UserID    uint `gorm:"index;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID                uint `gorm:"primaryKey;autoIncrement"`
+	WebhookEndpoint   WebhookEndpoint
+	WebhookEndpointID uint      `gorm:"index;not null"`
+	Event             string    `gorm:"size:100;index;not null"`
+	Payload           string    `gorm:"type:text;not null"`
+	Status            string    `gorm:"size:20;index;not null"`
+	Attempts          int       `gorm:"not null;default:0"`
+	NextAttemptAt     time.Time `gorm:"index"`
+	ResponseStatus    int
+	ResponseBody      string `gorm:"type:text"`
+	Error             string `gorm:"type:text"`
+	DeliveredAt       *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// DeviceToken belongs to a logged in user, or only to the app installation for anonymous jamaah
+type DeviceToken struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement"`
+	Token          string `gorm:"size:255;uniqueIndex;not null"`
+	Provider       string `gorm:"size:10;not null"`
+	InstallationID string `gorm:"size:100;index"`
+	User           *User
+	UserID         *uint `gorm:"index"`
+	Subscriptions  []PushSubscription
+	LastSeenAt     time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type PushSubscription struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	DeviceTokenID uint   `gorm:"uniqueIndex:idx_push_subscription;not null"`
+	Topic         string `gorm:"size:100;uniqueIndex:idx_push_subscription;index;not null"`
+	CreatedAt     time.Time
+}
+
+// PushDispatch logs every notification sent, the unique key keeps reminders from going out twice
+type PushDispatch struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	Key        string `gorm:"column:dispatch_key;size:150;uniqueIndex;not null"`
+	Topics     string `gorm:"size:255;not null"`
+	Title      string `gorm:"size:255;not null"`
+	Body       string `gorm:"type:text"`
+	Recipients int
+	Failures   int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type EmailOutbox struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement"`
+	Key           *string   `gorm:"column:dedupe_key;size:150;uniqueIndex"`
+	Recipient     string    `gorm:"size:100;not null"`
+	Template      string    `gorm:"size:50;index;not null"`
+	Subject       string    `gorm:"size:255;not null"`
+	HTML          string    `gorm:"type:text"`
+	Text          string    `gorm:"type:text"`
+	Status        string    `gorm:"size:20;index;not null"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string    `gorm:"type:text"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type MessageTemplate struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:50;uniqueIndex;not null"`
+	Body      string `gorm:"type:text;not null"`
+	User      User
+	UserID    uint `gorm:"index;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Tables lists every table in the order AutoMigrate used to create them
+var Tables = []interface{}{
+	&User{}, &Role{}, &Announcement{}, &Article{}, &Category{}, &StudyRundown{}, &StudyVideo{}, &AuditLog{}, &AnnouncementRevision{}, &Review{}, &Upload{}, &MediaAsset{}, &MediaReference{}, &AudioRecording{}, &WebhookEndpoint{}, &WebhookDelivery{}, &DeviceToken{}, &PushSubscription{}, &PushDispatch{}, &EmailOutbox{}, &MessageTemplate{},
+}
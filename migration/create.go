@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+const goTemplate = `package migration
+
+import "gorm.io/gorm"
+
+func init() {
+	Register(Migration{
+		Version: %q,
+		Name:    %q,
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
+`
+
+// Create writes an empty migration into dir, the migration package directory. SQL migrations
+// go to dir/sql and are embedded, so the binary has to be rebuilt before they can run
+func Create(dir string, name string, kind string, now time.Time) ([]string, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", "_"))
+	if !migrationName.MatchString(name) {
+		return nil, errors.New("migration name may only contain letters, digits and underscores")
+	}
+	version := now.UTC().Format("20060102150405")
+
+	var files map[string]string
+	switch kind {
+	case "sql":
+		files = map[string]string{
+			filepath.Join(dir, "sql", version+"_"+name+".up.sql"):   "-- " + name + "\n",
+			filepath.Join(dir, "sql", version+"_"+name+".down.sql"): "-- revert " + name + "\n",
+		}
+	case "go":
+		files = map[string]string{
+			filepath.Join(dir, version+"_"+name+".go"): fmt.Sprintf(goTemplate, version, name),
+		}
+	default:
+		return nil, errors.New("migration kind must be sql or go")
+	}
+
+	var created []string
+	for path, content := range files {
+		err := os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+	sort.Strings(created)
+	return created, nil
+}
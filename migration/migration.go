@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Migration changes the schema from one version to the next. Version is the creation time
+// as YYYYMMDDHHMMSS, so migrations written on different branches still sort sensibly
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+//go:embed sql
+var sqlFiles embed.FS
+
+var (
+	registered []Migration
+	fileName   = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(up|down)\.sql$`)
+)
+
+// Register adds a Go migration, call it from an init function next to the migration
+func Register(migration Migration) {
+	registered = append(registered, migration)
+}
+
+// All returns the Go and SQL migrations ordered by version
+func All() ([]Migration, error) {
+	byVersion := map[string]*Migration{}
+	for i := range registered {
+		migration := registered[i]
+		if _, exists := byVersion[migration.Version]; exists {
+			return nil, fmt.Errorf("migration %s is defined twice", migration.Version)
+		}
+		byVersion[migration.Version] = &migration
+	}
+
+	err := fs.WalkDir(sqlFiles, "sql", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(filePath, ".sql") {
+			return err
+		}
+
+		match := fileName.FindStringSubmatch(path.Base(filePath))
+		if match == nil {
+			return fmt.Errorf("migration file %s must be named <version>_<name>.up.sql or .down.sql", filePath)
+		}
+		content, err := sqlFiles.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		migration, exists := byVersion[match[1]]
+		if !exists {
+			migration = &Migration{Version: match[1], Name: match[2]}
+			byVersion[match[1]] = migration
+		}
+		if migration.Name != match[2] {
+			return fmt.Errorf("migration %s is defined twice", match[1])
+		}
+		if match[3] == "up" {
+			migration.Up = execSQL(string(content))
+		} else {
+			migration.Down = execSQL(string(content))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migration %s_%s has no up step", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// execSQL runs a whole file in one call, for mysql the dsn needs multiStatements=true
+func execSQL(content string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if strings.TrimSpace(content) == "" {
+			return nil
+		}
+		return tx.Exec(content).Error
+	}
+}
@@ -0,0 +1,188 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// schemaMigration is a row of the table that records which migrations ran
+type schemaMigration struct {
+	Version   string `gorm:"primaryKey;size:14"`
+	Name      string `gorm:"size:255;not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status tells whether a migration ran, AppliedAt is nil for pending ones
+type Status struct {
+	Migration Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.AutoMigrate(&schemaMigration{})
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db, migrations}, nil
+}
+
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) Pending() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies pending migrations in order, all of them when steps is 0
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		err := m.transaction(func(tx *gorm.DB) error {
+			errUp := migration.Up(tx)
+			if errUp != nil {
+				return errUp
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down rolls back the latest applied migrations, newest first
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].AppliedAt != nil {
+			applied = append(applied, statuses[i].Migration)
+		}
+	}
+	if steps > 0 && steps < len(applied) {
+		applied = applied[:steps]
+	}
+
+	var done []Migration
+	for _, migration := range applied {
+		if migration.Down == nil {
+			return done, fmt.Errorf("migration %s_%s can not be rolled back", migration.Version, migration.Name)
+		}
+		err := m.transaction(func(tx *gorm.DB) error {
+			errDown := migration.Down(tx)
+			if errDown != nil {
+				return errDown
+			}
+			return tx.Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// transaction runs one migration step. SQLite ignores PRAGMA foreign_keys inside a transaction, which
+// its gorm driver relies on to drop and rebuild tables, so the checks are deferred to the commit instead
+func (m *Migrator) transaction(step func(tx *gorm.DB) error) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "sqlite" {
+			err := tx.Exec("PRAGMA defer_foreign_keys = ON").Error
+			if err != nil {
+				return err
+			}
+		}
+		return step(tx)
+	})
+}
+
+func (m *Migrator) applied() (map[string]schemaMigration, error) {
+	var rows []schemaMigration
+	err := m.db.Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[string]schemaMigration{}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// ErrPending is returned by Check when the schema is behind the code
+var ErrPending = errors.New("database has pending migrations")
+
+// Check fails with ErrPending and the list of pending versions when migrations have not run
+func (m *Migrator) Check() error {
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	versions := ""
+	for _, migration := range pending {
+		versions += "\n  - " + migration.Version + "_" + migration.Name
+	}
+	return fmt.Errorf("%w, run `migrate up` first:%s", ErrPending, versions)
+}
@@ -0,0 +1,113 @@
+package migration
+
+import (
+	"errors"
+	"nurul-iman-blok-m/config"
+	"nurul-iman-blok-m/database"
+	"strings"
+	"testing"
+)
+
+func TestMigratorUpAndDown(t *testing.T) {
+	db, err := database.Open(config.DatabaseConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Check(); !errors.Is(err, ErrPending) {
+		t.Fatalf("fresh database: got %v, want ErrPending", err)
+	}
+
+	tests := []struct {
+		name        string
+		run         func() ([]Migration, error)
+		wantDone    int
+		wantPending int
+	}{
+		{"first step up", func() ([]Migration, error) { return migrator.Up(1) }, 1, len(all) - 1},
+		{"rest up", func() ([]Migration, error) { return migrator.Up(0) }, len(all) - 1, 0},
+		{"nothing left to apply", func() ([]Migration, error) { return migrator.Up(0) }, 0, 0},
+		{"one step down", func() ([]Migration, error) { return migrator.Down(1) }, 1, 1},
+		{"up again", func() ([]Migration, error) { return migrator.Up(0) }, 1, 0},
+		{"everything down", func() ([]Migration, error) { return migrator.Down(0) }, len(all), len(all)},
+		{"up from scratch", func() ([]Migration, error) { return migrator.Up(0) }, len(all), 0},
+	}
+
+	for _, test := range tests {
+		done, err := test.run()
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(done) != test.wantDone {
+			t.Errorf("%s: ran %d migrations, want %d", test.name, len(done), test.wantDone)
+		}
+		pending, err := migrator.Pending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != test.wantPending {
+			t.Errorf("%s: %d pending, want %d", test.name, len(pending), test.wantPending)
+		}
+	}
+
+	if err := migrator.Check(); err != nil {
+		t.Errorf("migrated database: %v", err)
+	}
+	for _, table := range []string{"users", "announcements", "push_dispatches"} {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s missing after up", table)
+		}
+	}
+}
+
+func TestUniqueSlugsRenamesDuplicates(t *testing.T) {
+	db, err := database.Open(config.DatabaseConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// rows written before the index existed, two of them share a slug and one is already suffixed
+	seed := []string{
+		"INSERT INTO roles (id, role_name) VALUES (1, 'admin')",
+		"INSERT INTO users (id, name, email, password, role_id) VALUES (1, 'admin', 'admin@example.com', 'x', 1)",
+		"INSERT INTO announcements (id, title, description, images, user_id, slug) VALUES (1, 'a', 'a', '', 1, 'kajian')",
+		"INSERT INTO announcements (id, title, description, images, user_id, slug) VALUES (2, 'a', 'a', '', 1, 'kajian-2')",
+		"INSERT INTO announcements (id, title, description, images, user_id, slug) VALUES (3, 'a', 'a', '', 1, 'kajian')",
+	}
+	for _, statement := range seed {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := migrator.Up(0); err != nil {
+		t.Fatal(err)
+	}
+
+	var slugs []string
+	if err := db.Table("announcements").Order("id").Pluck("slug", &slugs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(slugs, ","), "kajian,kajian-2,kajian-3"; got != want {
+		t.Errorf("slugs after up: %s, want %s", got, want)
+	}
+
+	err = db.Exec("INSERT INTO announcements (title, description, images, user_id, slug) VALUES ('a', 'a', '', 1, 'kajian')").Error
+	if !database.IsDuplicateKey(err) {
+		t.Errorf("inserting a taken slug: got %v, want a duplicate key error", err)
+	}
+}
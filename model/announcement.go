@@ -1,15 +1,24 @@
 package model
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type Announcement struct {
-	ID          uint   `gorm:"primaryKey;autoIncrement"`
-	Title       string `gorm:"size:255;not null"`
-	Description string `gorm:"type:text;not null"`
-	Images      string `gorm:"size:100;not null"`
-	User        User
-	UserID      uint   `gorm:"index;not null"`
-	Slug        string `gorm:"size:255;not null"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             uint   `gorm:"primaryKey;autoIncrement"`
+	Title          string `gorm:"size:255;not null"`
+	Description    string `gorm:"type:text;not null"`
+	Images         string `gorm:"size:255;not null"`
+	ImageMedium    string `gorm:"size:255"`
+	ImageThumbnail string `gorm:"size:255"`
+	User           User
+	UserID         uint       `gorm:"index;not null"`
+	Slug           string     `gorm:"size:255;not null;uniqueIndex"`
+	Status         string     `gorm:"size:20;not null;default:published;index"`
+	PublishAt      *time.Time `gorm:"index"`
+	ExpireAt       *time.Time `gorm:"index"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
 }
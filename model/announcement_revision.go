@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type AnnouncementRevision struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement"`
+	AnnouncementID uint   `gorm:"index;not null"`
+	Revision       uint   `gorm:"not null"`
+	Title          string `gorm:"size:255;not null"`
+	Description    string `gorm:"type:text;not null"`
+	Images         string `gorm:"size:255;not null"`
+	ImageMedium    string `gorm:"size:255"`
+	ImageThumbnail string `gorm:"size:255"`
+	Slug           string `gorm:"size:255;not null"`
+	User           User
+	UserID         uint `gorm:"index"`
+	CreatedAt      time.Time
+}
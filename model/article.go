@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type Article struct {
 	ID          uint   `gorm:"primaryKey;autoIncrement;not null"`
@@ -10,7 +13,8 @@ type Article struct {
 	UserID      uint `gorm:"index;not null"`
 	Category    Category
 	CategoryID  uint   `gorm:"index;not null"`
-	Slug        string `gorm:"size:255;not null"`
+	Slug        string `gorm:"size:255;not null;uniqueIndex"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
 }
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type AudioRecording struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement;not null"`
+	Title          string `gorm:"size:100;not null"`
+	Description    string `gorm:"type:text"`
+	StudyRundown   StudyRundown
+	StudyRundownID uint `gorm:"index;not null"`
+	Speaker        User
+	SpeakerID      uint   `gorm:"index;not null"`
+	URL            string `gorm:"size:255;not null"`
+	ContentType    string `gorm:"size:100;not null"`
+	Size           int64
+	Duration       int // seconds
+	RecordedAt     time.Time
+	User           User
+	UserID         uint `gorm:"index;not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	ActorID    uint      `gorm:"index"`
+	ActorName  string    `gorm:"size:100"`
+	Action     string    `gorm:"size:20;index;not null"`
+	EntityType string    `gorm:"size:50;index;not null"`
+	EntityID   uint      `gorm:"index"`
+	Changes    string    `gorm:"type:text"`
+	IPAddress  string    `gorm:"size:45"`
+	CreatedAt  time.Time `gorm:"index"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type EmailOutbox struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement"`
+	Key           *string   `gorm:"column:dedupe_key;size:150;uniqueIndex"`
+	Recipient     string    `gorm:"size:100;not null"`
+	Template      string    `gorm:"size:50;index;not null"`
+	Subject       string    `gorm:"size:255;not null"`
+	HTML          string    `gorm:"type:text"`
+	Text          string    `gorm:"type:text"`
+	Status        string    `gorm:"size:20;index;not null"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string    `gorm:"type:text"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type MediaAsset struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	ParentID    *uint  `gorm:"index"`
+	Variant     string `gorm:"size:20"`
+	Key         string `gorm:"column:object_key;size:255;uniqueIndex;not null"`
+	URL         string `gorm:"size:255;index;not null"`
+	FileName    string `gorm:"size:255"`
+	ContentType string `gorm:"size:100;not null"`
+	Size        int64
+	Width       int
+	Height      int
+	User        User
+	UserID      uint             `gorm:"index"`
+	Variants    []MediaAsset     `gorm:"foreignKey:ParentID"`
+	References  []MediaReference `gorm:"foreignKey:MediaAssetID"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type MediaReference struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	MediaAssetID uint   `gorm:"index;not null"`
+	EntityType   string `gorm:"size:50;index:idx_media_reference_entity;not null"`
+	EntityID     uint   `gorm:"index:idx_media_reference_entity;not null"`
+	Field        string `gorm:"size:50;not null"`
+	CreatedAt    time.Time
+}
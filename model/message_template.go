@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type MessageTemplate struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:50;uniqueIndex;not null"`
+	Body      string `gorm:"type:text;not null"`
+	User      User
+	UserID    uint `gorm:"index;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
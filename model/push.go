@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// DeviceToken belongs to a logged in user, or only to the app installation for anonymous jamaah
+type DeviceToken struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement"`
+	Token          string `gorm:"size:255;uniqueIndex;not null"`
+	Provider       string `gorm:"size:10;not null"`
+	InstallationID string `gorm:"size:100;index"`
+	User           *User
+	UserID         *uint `gorm:"index"`
+	Subscriptions  []PushSubscription
+	LastSeenAt     time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type PushSubscription struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	DeviceTokenID uint   `gorm:"uniqueIndex:idx_push_subscription;not null"`
+	Topic         string `gorm:"size:100;uniqueIndex:idx_push_subscription;index;not null"`
+	CreatedAt     time.Time
+}
+
+// PushDispatch is the outbox of notifications, the unique key keeps reminders from going out twice
+type PushDispatch struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	Key           string `gorm:"column:dispatch_key;size:150;uniqueIndex;not null"`
+	Topics        string `gorm:"size:255;not null"`
+	Title         string `gorm:"size:255;not null"`
+	Body          string `gorm:"type:text"`
+	Data          string `gorm:"type:text"`
+	Recipients    int
+	Failures      int
+	Status        string    `gorm:"size:20;index;not null"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string    `gorm:"type:text"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
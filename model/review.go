@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type Review struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	ContentType string `gorm:"size:50;index;not null"`
+	ContentID   uint   `gorm:"index;not null"`
+	Title       string `gorm:"size:255;not null"`
+	Author      User   `gorm:"foreignKey:AuthorID"`
+	AuthorID    uint   `gorm:"index;not null"`
+	Reviewer    User   `gorm:"foreignKey:ReviewerID"`
+	ReviewerID  *uint  `gorm:"index"`
+	Status      string `gorm:"size:20;index;not null"`
+	Comment     string `gorm:"type:text"`
+	DecidedAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type StudyRundown struct {
 	ID           uint   `gorm:"primaryKey;autoIncrement;not null"`
@@ -12,4 +15,5 @@ type StudyRundown struct {
 	Time         string `gorm:"size:100;not null"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
@@ -7,7 +7,7 @@ type StudyVideo struct {
 	Title     string `gorm:"size:100;not null"`
 	thumbnail string `gorm:"size:100;not null"`
 	Url       string `gorm:"size:255;not null"`
-	Slug      string
+	Slug      string `gorm:"size:255;uniqueIndex"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
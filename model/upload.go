@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type Upload struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Key         string `gorm:"column:object_key;size:255;uniqueIndex;not null"`
+	URL         string `gorm:"size:255"`
+	FileName    string `gorm:"size:255"`
+	ContentType string `gorm:"size:100;not null"`
+	Size        int64
+	Status      string `gorm:"size:20;index;not null"`
+	EntityType  string `gorm:"size:50;index"`
+	EntityID    uint   `gorm:"index"`
+	User        User
+	UserID      uint `gorm:"index;not null"`
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type WebhookEndpoint struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	URL       string `gorm:"size:255;not null"`
+	Secret    string `gorm:"size:100;not null"`
+	Events    string `gorm:"size:500;not null"` // comma separated event names
+	Active    bool   `gorm:"not null;default:true"`
+	User      User
+	UserID    uint `gorm:"index;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID                uint `gorm:"primaryKey;autoIncrement"`
+	WebhookEndpoint   WebhookEndpoint
+	WebhookEndpointID uint      `gorm:"index;not null"`
+	Event             string    `gorm:"size:100;index;not null"`
+	Payload           string    `gorm:"type:text;not null"`
+	Status            string    `gorm:"size:20;index;not null"`
+	Attempts          int       `gorm:"not null;default:0"`
+	NextAttemptAt     time.Time `gorm:"index"`
+	ResponseStatus    int
+	ResponseBody      string `gorm:"type:text"`
+	Error             string `gorm:"type:text"`
+	DeliveredAt       *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
@@ -0,0 +1,127 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+)
+
+// apnsProvider uses token based authentication, the .p8 key from the apple developer account
+type apnsProvider struct {
+	key    *ecdsa.PrivateKey
+	keyID  string
+	teamID string
+	topic  string
+	host   string
+	client *http.Client
+
+	mutex    sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// NewAPNSProvider takes the bundle id of the app as topic
+func NewAPNSProvider(keyFile string, keyID string, teamID string, topic string, production bool) (*apnsProvider, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(content)
+	if err != nil {
+		return nil, err
+	}
+
+	host := apnsSandboxHost
+	if production {
+		host = apnsProductionHost
+	}
+
+	// apple requires http/2, the default transport negotiates it over tls
+	return &apnsProvider{key: key, keyID: keyID, teamID: teamID, topic: topic, host: host, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (p *apnsProvider) Send(ctx context.Context, tokens []string, message Message) ([]string, error) {
+	bearer, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": message.Title, "body": message.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range message.Data {
+		payload[key] = value
+	}
+	body, _ := json.Marshal(payload)
+
+	var invalid []string
+	for _, token := range tokens {
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/3/device/"+token, bytes.NewReader(body))
+		if err != nil {
+			return invalid, err
+		}
+		request.Header.Set("authorization", "bearer "+bearer)
+		request.Header.Set("apns-topic", p.topic)
+		request.Header.Set("apns-push-type", "alert")
+
+		response, err := p.client.Do(request)
+		if err != nil {
+			return invalid, err
+		}
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		response.Body.Close()
+
+		switch {
+		case response.StatusCode == http.StatusOK:
+		case response.StatusCode == http.StatusGone || strings.Contains(string(responseBody), "BadDeviceToken"):
+			invalid = append(invalid, token)
+		default:
+			return invalid, fmt.Errorf("apns answered %d: %s", response.StatusCode, responseBody)
+		}
+	}
+	return invalid, nil
+}
+
+// token is reused for 50 minutes, apple rejects tokens older than an hour and refreshing too often
+func (p *apnsProvider) token() (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.jwt != "" && time.Since(p.issuedAt) < 50*time.Minute {
+		return p.jwt, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.keyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+
+	p.jwt = signed
+	p.issuedAt = now
+	return p.jwt, nil
+}
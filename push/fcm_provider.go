@@ -0,0 +1,163 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// serviceAccount is the part of the firebase service account json the provider needs
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// fcmProvider talks to the FCM HTTP v1 api with an oauth token minted from the service account
+type fcmProvider struct {
+	account serviceAccount
+	client  *http.Client
+
+	mutex       sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewFCMProvider(credentialsFile string) (*fcmProvider, error) {
+	content, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var account serviceAccount
+	err = json.Unmarshal(content, &account)
+	if err != nil {
+		return nil, err
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("incomplete firebase service account")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &fcmProvider{account: account, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (p *fcmProvider) Send(ctx context.Context, tokens []string, message Message) ([]string, error) {
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", p.account.ProjectID)
+
+	// the v1 api sends to one device per request
+	var invalid []string
+	for _, token := range tokens {
+		body, _ := json.Marshal(map[string]interface{}{
+			"message": map[string]interface{}{
+				"token":        token,
+				"notification": map[string]string{"title": message.Title, "body": message.Body},
+				"data":         message.Data,
+			},
+		})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return invalid, err
+		}
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := p.client.Do(request)
+		if err != nil {
+			return invalid, err
+		}
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		response.Body.Close()
+
+		switch {
+		case response.StatusCode == http.StatusOK:
+		case response.StatusCode == http.StatusNotFound || strings.Contains(string(responseBody), "UNREGISTERED"):
+			invalid = append(invalid, token)
+		case response.StatusCode == http.StatusBadRequest && strings.Contains(string(responseBody), "registration token"):
+			invalid = append(invalid, token)
+		default:
+			return invalid, fmt.Errorf("fcm answered %d: %s", response.StatusCode, responseBody)
+		}
+	}
+	return invalid, nil
+}
+
+// token exchanges a signed jwt for an access token and keeps it until shortly before it expires
+func (p *fcmProvider) token(ctx context.Context) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.account.PrivateKey))
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return "", fmt.Errorf("fcm token request answered %d", response.StatusCode)
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
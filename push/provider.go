@@ -0,0 +1,71 @@
+package push
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+const (
+	ProviderFCM  = "fcm"
+	ProviderAPNS = "apns"
+)
+
+type Message struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Provider delivers to one push network, it returns the tokens the network reported as no longer valid
+type Provider interface {
+	Send(ctx context.Context, tokens []string, message Message) ([]string, error)
+}
+
+// FakeMessage is one Send call recorded by the fake provider
+type FakeMessage struct {
+	Tokens  []string
+	Message Message
+}
+
+// maxFakeMessages keeps a long running development server from growing without bound
+const maxFakeMessages = 100
+
+// FakeProvider keeps the last messages in memory, used in development and tests instead of FCM or APNs.
+// Tokens listed in Invalid are reported back as unregistered.
+type FakeProvider struct {
+	mutex   sync.Mutex
+	Sent    []FakeMessage
+	Invalid map[string]bool
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{Invalid: map[string]bool{}}
+}
+
+func (p *FakeProvider) Send(ctx context.Context, tokens []string, message Message) ([]string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.Sent = append(p.Sent, FakeMessage{Tokens: tokens, Message: message})
+	if len(p.Sent) > maxFakeMessages {
+		p.Sent = append([]FakeMessage{}, p.Sent[len(p.Sent)-maxFakeMessages:]...)
+	}
+	log.Printf("push: %q to %d devices", message.Title, len(tokens))
+
+	var invalid []string
+	for _, token := range tokens {
+		if p.Invalid[token] {
+			invalid = append(invalid, token)
+		}
+	}
+	return invalid, nil
+}
+
+// Messages returns a copy of what was sent so far
+func (p *FakeProvider) Messages() []FakeMessage {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]FakeMessage{}, p.Sent...)
+}
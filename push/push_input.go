@@ -0,0 +1,17 @@
+package push
+
+type DeviceInput struct {
+	Token          string   `json:"token" binding:"required"`
+	Provider       string   `json:"provider" binding:"required,oneof=fcm apns"`
+	InstallationID string   `json:"installation_id" binding:"required"`
+	Topics         []string `json:"topics"`
+}
+
+type TokenInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type TopicInput struct {
+	Token  string   `json:"token" binding:"required"`
+	Topics []string `json:"topics" binding:"dive,required"`
+}
@@ -0,0 +1,31 @@
+package push
+
+import (
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type DeviceFormatter struct {
+	ID             uint      `json:"id"`
+	Provider       string    `json:"provider"`
+	InstallationID string    `json:"installation_id"`
+	UserID         *uint     `json:"user_id"`
+	Topics         []string  `json:"topics"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+}
+
+func DeviceJsonFormatter(device model.DeviceToken) DeviceFormatter {
+	topics := []string{}
+	for _, subscription := range device.Subscriptions {
+		topics = append(topics, subscription.Topic)
+	}
+
+	return DeviceFormatter{
+		ID:             device.ID,
+		Provider:       device.Provider,
+		InstallationID: device.InstallationID,
+		UserID:         device.UserID,
+		Topics:         topics,
+		LastSeenAt:     device.LastSeenAt,
+	}
+}
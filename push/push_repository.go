@@ -0,0 +1,119 @@
+package push
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type PushRepository interface {
+	FindByToken(ctx context.Context, token string) (model.DeviceToken, error)
+	SaveDevice(ctx context.Context, device model.DeviceToken) (model.DeviceToken, error)
+	DeleteTokens(ctx context.Context, tokens []string) error
+	ReplaceSubscriptions(ctx context.Context, deviceID uint, topics []string) error
+	GetSubscribedDevices(ctx context.Context, topics []string) ([]model.DeviceToken, error)
+	CreateDispatch(ctx context.Context, dispatch model.PushDispatch) (model.PushDispatch, bool, error)
+	SaveDispatch(ctx context.Context, dispatch model.PushDispatch) error
+	GetDueDispatches(ctx context.Context, now time.Time, limit int) ([]model.PushDispatch, error)
+	GetRundownsOn(ctx context.Context, dates []string) ([]model.StudyRundown, error)
+}
+
+type pushRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *pushRepository {
+	return &pushRepository{db}
+}
+
+func (r *pushRepository) FindByToken(ctx context.Context, token string) (model.DeviceToken, error) {
+	var device model.DeviceToken
+	err := r.db.WithContext(ctx).Preload("Subscriptions").Where("token = ?", token).Find(&device).Error
+	if err != nil {
+		return device, err
+	}
+	return device, nil
+}
+
+func (r *pushRepository) SaveDevice(ctx context.Context, device model.DeviceToken) (model.DeviceToken, error) {
+	err := r.db.WithContext(ctx).Omit(clause.Associations).Save(&device).Error
+	if err != nil {
+		return device, err
+	}
+	return device, nil
+}
+
+func (r *pushRepository) DeleteTokens(ctx context.Context, tokens []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("device_token_id IN (?)", tx.Model(&model.DeviceToken{}).Select("id").Where("token IN ?", tokens)).
+			Delete(&model.PushSubscription{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("token IN ?", tokens).Delete(&model.DeviceToken{}).Error
+	})
+}
+
+func (r *pushRepository) ReplaceSubscriptions(ctx context.Context, deviceID uint, topics []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("device_token_id = ?", deviceID).Delete(&model.PushSubscription{}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, topic := range topics {
+			err = tx.Create(&model.PushSubscription{DeviceTokenID: deviceID, Topic: topic}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *pushRepository) GetSubscribedDevices(ctx context.Context, topics []string) ([]model.DeviceToken, error) {
+	var devices []model.DeviceToken
+	err := r.db.WithContext(ctx).Where("id IN (?)", r.db.WithContext(ctx).Model(&model.PushSubscription{}).Select("device_token_id").Where("topic IN ?", topics)).
+		Find(&devices).Error
+	if err != nil {
+		return devices, err
+	}
+	return devices, nil
+}
+
+// CreateDispatch reports false when a dispatch with the same key was already made
+func (r *pushRepository) CreateDispatch(ctx context.Context, dispatch model.PushDispatch) (model.PushDispatch, bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&dispatch)
+	if result.Error != nil {
+		return dispatch, false, result.Error
+	}
+	return dispatch, result.RowsAffected == 1, nil
+}
+
+func (r *pushRepository) SaveDispatch(ctx context.Context, dispatch model.PushDispatch) error {
+	err := r.db.WithContext(ctx).Save(&dispatch).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *pushRepository) GetDueDispatches(ctx context.Context, now time.Time, limit int) ([]model.PushDispatch, error) {
+	var dispatches []model.PushDispatch
+	err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", StatusPending, now).Order("next_attempt_at asc").Limit(limit).Find(&dispatches).Error
+	if err != nil {
+		return dispatches, err
+	}
+	return dispatches, nil
+}
+
+func (r *pushRepository) GetRundownsOn(ctx context.Context, dates []string) ([]model.StudyRundown, error) {
+	var rundowns []model.StudyRundown
+	err := r.db.WithContext(ctx).Preload("User").Where("schedule_date IN ?", dates).Find(&rundowns).Error
+	if err != nil {
+		return rundowns, err
+	}
+	return rundowns, nil
+}
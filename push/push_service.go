@@ -0,0 +1,373 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/study_rundown"
+	"nurul-iman-blok-m/worker"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TopicAnnouncements   = "announcements"
+	TopicRundowns        = "rundowns"
+	TopicPrayerReminders = "prayer-reminders"
+	// ustadz topics follow the kajian of one ustadz, e.g. ustadz-12
+	topicUstadzPrefix = "ustadz-"
+
+	// ReminderLead is how long before a kajian starts the reminder goes out
+	ReminderLead = 30 * time.Minute
+
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+
+	// MaxAttempts gives up after about fifteen minutes, a later reminder would arrive after the kajian started
+	MaxAttempts = 5
+
+	baseBackoff   = time.Minute
+	maxBackoff    = 10 * time.Minute
+	batchSize     = 500
+	dispatchBatch = 20
+	maxLogLength  = 2000
+)
+
+var errNotRegistered = apperr.NotFound("device_not_registered", "device not registered")
+
+func UstadzTopic(userID uint) string {
+	return topicUstadzPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+func ValidTopic(topic string) bool {
+	switch topic {
+	case TopicAnnouncements, TopicRundowns, TopicPrayerReminders:
+		return true
+	}
+	if strings.HasPrefix(topic, topicUstadzPrefix) {
+		_, err := strconv.ParseUint(strings.TrimPrefix(topic, topicUstadzPrefix), 10, 64)
+		return err == nil
+	}
+	return false
+}
+
+type PushService interface {
+	RegisterDevice(ctx context.Context, input DeviceInput, userID *uint) (model.DeviceToken, error)
+	UnregisterDevice(ctx context.Context, input TokenInput) error
+	SetTopics(ctx context.Context, input TopicInput) (model.DeviceToken, error)
+	Enqueue(ctx context.Context, key string, topics []string, message Message) (model.PushDispatch, bool, error)
+	ProcessDue(ctx context.Context) (int, error)
+	AnnouncementPublished(ctx context.Context, announcement model.Announcement)
+	SendRundownReminders(ctx context.Context) (int, error)
+}
+
+type pushService struct {
+	repository PushRepository
+	providers  map[string]Provider
+	location   *time.Location
+}
+
+// NewService takes one provider per network, fcm and apns, and the time zone the rundown schedule is written in
+func NewService(repository PushRepository, providers map[string]Provider, location *time.Location) *pushService {
+	return &pushService{repository, providers, location}
+}
+
+// RegisterDevice is called by the app on every start, the same token updates the existing device.
+// New devices follow announcements until the app sends its own topics.
+func (s *pushService) RegisterDevice(ctx context.Context, input DeviceInput, userID *uint) (model.DeviceToken, error) {
+	for _, topic := range input.Topics {
+		if !ValidTopic(topic) {
+			return model.DeviceToken{}, apperr.Validation("unknown_topic", "unknown topic: "+topic)
+		}
+	}
+
+	device, err := s.repository.FindByToken(ctx, input.Token)
+	if err != nil {
+		return device, err
+	}
+	isNew := device.ID == 0
+
+	device.Token = input.Token
+	device.Provider = input.Provider
+	device.InstallationID = input.InstallationID
+	device.UserID = userID
+	device.LastSeenAt = time.Now()
+
+	saved, err := s.repository.SaveDevice(ctx, device)
+	if err != nil {
+		return saved, err
+	}
+
+	topics := input.Topics
+	if topics == nil && isNew {
+		topics = []string{TopicAnnouncements}
+	}
+	if topics != nil {
+		err = s.repository.ReplaceSubscriptions(ctx, saved.ID, topics)
+		if err != nil {
+			return saved, err
+		}
+	}
+
+	return s.repository.FindByToken(ctx, saved.Token)
+}
+
+func (s *pushService) UnregisterDevice(ctx context.Context, input TokenInput) error {
+	return s.repository.DeleteTokens(ctx, []string{input.Token})
+}
+
+func (s *pushService) SetTopics(ctx context.Context, input TopicInput) (model.DeviceToken, error) {
+	for _, topic := range input.Topics {
+		if !ValidTopic(topic) {
+			return model.DeviceToken{}, apperr.Validation("unknown_topic", "unknown topic: "+topic)
+		}
+	}
+
+	device, err := s.repository.FindByToken(ctx, input.Token)
+	if err != nil {
+		return device, err
+	}
+	if device.ID == 0 {
+		return device, errNotRegistered
+	}
+
+	err = s.repository.ReplaceSubscriptions(ctx, device.ID, input.Topics)
+	if err != nil {
+		return device, err
+	}
+	return s.repository.FindByToken(ctx, device.Token)
+}
+
+// Enqueue queues the message for every device subscribed to one of the topics, the dispatch worker sends it.
+// The key makes it idempotent, it reports false when a dispatch with the same key was queued before
+func (s *pushService) Enqueue(ctx context.Context, key string, topics []string, message Message) (model.PushDispatch, bool, error) {
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		return model.PushDispatch{}, false, err
+	}
+
+	return s.repository.CreateDispatch(ctx, model.PushDispatch{
+		Key:           key,
+		Topics:        strings.Join(topics, ","),
+		Title:         message.Title,
+		Body:          message.Body,
+		Data:          string(data),
+		Status:        StatusPending,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// ProcessDue sends the dispatches whose next attempt is due and reschedules the failed ones
+func (s *pushService) ProcessDue(ctx context.Context) (int, error) {
+	dispatches, err := s.repository.GetDueDispatches(ctx, time.Now(), dispatchBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, dispatch := range dispatches {
+		dispatch = s.send(ctx, dispatch)
+		if ctx.Err() != nil {
+			// cut off by shutdown, the dispatch stays due and is sent again after the restart
+			return sent, nil
+		}
+		err = s.repository.SaveDispatch(ctx, dispatch)
+		if err != nil {
+			return sent, err
+		}
+		if dispatch.Status == StatusSent {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// send delivers one dispatch to every subscribed device. A failed batch retries the whole dispatch,
+// devices of the batches that went through may get the notification twice
+func (s *pushService) send(ctx context.Context, dispatch model.PushDispatch) model.PushDispatch {
+	dispatch.Attempts++
+	dispatch.Recipients = 0
+	dispatch.Failures = 0
+
+	err := s.sendToDevices(ctx, &dispatch)
+	if err == nil {
+		now := time.Now()
+		dispatch.Status = StatusSent
+		dispatch.LastError = ""
+		dispatch.SentAt = &now
+		return dispatch
+	}
+
+	dispatch.LastError = err.Error()
+	if len(dispatch.LastError) > maxLogLength {
+		dispatch.LastError = dispatch.LastError[:maxLogLength]
+	}
+	if dispatch.Attempts >= MaxAttempts {
+		dispatch.Status = StatusFailed
+		return dispatch
+	}
+	dispatch.NextAttemptAt = time.Now().Add(Backoff(dispatch.Attempts))
+	return dispatch
+}
+
+func (s *pushService) sendToDevices(ctx context.Context, dispatch *model.PushDispatch) error {
+	message := Message{Title: dispatch.Title, Body: dispatch.Body}
+	if dispatch.Data != "" {
+		err := json.Unmarshal([]byte(dispatch.Data), &message.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	topics := strings.Split(dispatch.Topics, ",")
+	devices, err := s.repository.GetSubscribedDevices(ctx, topics)
+	if err != nil {
+		return err
+	}
+
+	tokens := map[string][]string{}
+	for _, device := range devices {
+		tokens[device.Provider] = append(tokens[device.Provider], device.Token)
+	}
+
+	var invalid []string
+	var sendErr error
+	for providerName, providerTokens := range tokens {
+		provider, ok := s.providers[providerName]
+		if !ok {
+			dispatch.Failures += len(providerTokens)
+			continue
+		}
+
+		for start := 0; start < len(providerTokens); start += batchSize {
+			end := start + batchSize
+			if end > len(providerTokens) {
+				end = len(providerTokens)
+			}
+			batch := providerTokens[start:end]
+
+			rejected, err := provider.Send(ctx, batch, message)
+			invalid = append(invalid, rejected...)
+			if err != nil {
+				dispatch.Failures += len(batch) - len(rejected)
+				sendErr = err
+				continue
+			}
+			dispatch.Recipients += len(batch) - len(rejected)
+		}
+	}
+
+	// uninstalled apps leave dead tokens behind, the networks tell us which ones
+	if len(invalid) > 0 {
+		err = s.repository.DeleteTokens(ctx, invalid)
+		if err != nil {
+			return err
+		}
+	}
+	return sendErr
+}
+
+// Backoff doubles the wait after every failed attempt: 1m, 2m, 4m ... capped at 10m
+func Backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return wait
+}
+
+// AnnouncementPublished only queues the push, publishing must not wait for the push networks
+func (s *pushService) AnnouncementPublished(ctx context.Context, announcement model.Announcement) {
+	_, _, err := s.Enqueue(ctx, fmt.Sprintf("announcement:%d", announcement.ID), []string{TopicAnnouncements}, Message{
+		Title: announcement.Title,
+		Body:  summary(announcement.Description),
+		Data: map[string]string{
+			"type": "announcement",
+			"id":   strconv.FormatUint(uint64(announcement.ID), 10),
+			"slug": announcement.Slug,
+		},
+	})
+	if err != nil {
+		log.Printf("push for announcement %d not queued: %v", announcement.ID, err)
+	}
+}
+
+// SendRundownReminders queues a notification for the followers of a kajian when it starts within ReminderLead
+func (s *pushService) SendRundownReminders(ctx context.Context) (int, error) {
+	now := time.Now().In(s.location)
+
+	dates := append(study_rundown.ScheduleDates(now), study_rundown.ScheduleDates(now.Add(ReminderLead))...)
+
+	rundowns, err := s.repository.GetRundownsOn(ctx, dates)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, rundown := range rundowns {
+		start, ok := study_rundown.StartTime(rundown, s.location)
+		if !ok || now.Before(start.Add(-ReminderLead)) || !now.Before(start) {
+			continue
+		}
+
+		_, created, err := s.Enqueue(ctx, fmt.Sprintf("rundown:%d:%s", rundown.ID, start.Format(time.RFC3339)), []string{TopicRundowns, UstadzTopic(rundown.UserID)}, Message{
+			Title: rundown.Title,
+			Body:  fmt.Sprintf("Kajian bersama %s dimulai pukul %s", rundown.User.Name, start.Format("15:04")),
+			Data: map[string]string{
+				"type": "rundown",
+				"id":   strconv.FormatUint(uint64(rundown.ID), 10),
+			},
+		})
+		if err != nil {
+			log.Printf("push reminder for rundown %d not queued: %v", rundown.ID, err)
+			continue
+		}
+		if created {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// summary keeps the notification body short, the app opens the full announcement
+func summary(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= 120 {
+		return string(runes)
+	}
+	return string(runes[:117]) + "..."
+}
+
+func StartReminderScheduler(jobs *worker.Group, service PushService, interval time.Duration) {
+	jobs.Every(interval, false, func(ctx context.Context) {
+		queued, err := service.SendRundownReminders(ctx)
+		if err != nil {
+			log.Printf("push reminders failed: %v", err)
+			return
+		}
+		if queued > 0 {
+			log.Printf("push reminders queued for %d kajian", queued)
+		}
+	})
+}
+
+func StartDispatchWorker(jobs *worker.Group, service PushService, interval time.Duration) {
+	jobs.Every(interval, false, func(ctx context.Context) {
+		sent, err := service.ProcessDue(ctx)
+		if err != nil {
+			log.Printf("push dispatch failed: %v", err)
+			return
+		}
+		if sent > 0 {
+			log.Printf("push sent %d notifications", sent)
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"nurul-iman-blok-m/migration"
+	"nurul-iman-blok-m/model"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, providers map[string]Provider) (*pushService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:?_pragma=foreign_keys(1)"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to :memory: is a database of its own
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = migrator.Up(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewService(NewRepository(db), providers, time.UTC), db
+}
+
+func registerDevice(t *testing.T, service *pushService, token string, provider string) {
+	t.Helper()
+
+	_, err := service.RegisterDevice(context.Background(), DeviceInput{Token: token, Provider: provider, InstallationID: token}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failingProvider struct{}
+
+func (failingProvider) Send(ctx context.Context, tokens []string, message Message) ([]string, error) {
+	return nil, errors.New("network down")
+}
+
+func TestEnqueueIsIdempotent(t *testing.T) {
+	service, _ := newTestService(t, map[string]Provider{})
+	ctx := context.Background()
+
+	_, created, err := service.Enqueue(ctx, "announcement:1", []string{TopicAnnouncements}, Message{Title: "Kajian"})
+	if err != nil || !created {
+		t.Fatalf("first enqueue: created %v, err %v", created, err)
+	}
+	_, created, err = service.Enqueue(ctx, "announcement:1", []string{TopicAnnouncements}, Message{Title: "Kajian"})
+	if err != nil || created {
+		t.Fatalf("second enqueue: created %v, err %v", created, err)
+	}
+}
+
+func TestProcessDue(t *testing.T) {
+	tests := []struct {
+		name           string
+		provider       func(fake *FakeProvider) Provider
+		wantStatus     string
+		wantAttempts   int
+		wantRecipients int
+		wantMessages   int
+	}{
+		{
+			name:           "sent through the provider",
+			provider:       func(fake *FakeProvider) Provider { return fake },
+			wantStatus:     StatusSent,
+			wantAttempts:   1,
+			wantRecipients: 2,
+			wantMessages:   1,
+		},
+		{
+			name:         "failed send stays pending for a retry",
+			provider:     func(fake *FakeProvider) Provider { return failingProvider{} },
+			wantStatus:   StatusPending,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := NewFakeProvider()
+			service, db := newTestService(t, map[string]Provider{ProviderFCM: test.provider(fake)})
+			ctx := context.Background()
+
+			registerDevice(t, service, "token-a", ProviderFCM)
+			registerDevice(t, service, "token-b", ProviderFCM)
+
+			_, _, err := service.Enqueue(ctx, "announcement:7", []string{TopicAnnouncements}, Message{
+				Title: "Kajian Ahad",
+				Data:  map[string]string{"id": "7"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(fake.Messages()) != 0 {
+				t.Fatal("enqueue must not send")
+			}
+
+			_, err = service.ProcessDue(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var dispatch model.PushDispatch
+			err = db.Where("dispatch_key = ?", "announcement:7").First(&dispatch).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dispatch.Status != test.wantStatus || dispatch.Attempts != test.wantAttempts || dispatch.Recipients != test.wantRecipients {
+				t.Errorf("got status %q, attempts %d, recipients %d", dispatch.Status, dispatch.Attempts, dispatch.Recipients)
+			}
+			if test.wantStatus == StatusPending && !dispatch.NextAttemptAt.After(time.Now()) {
+				t.Errorf("retry not rescheduled, next attempt %v", dispatch.NextAttemptAt)
+			}
+
+			messages := fake.Messages()
+			if len(messages) != test.wantMessages {
+				t.Fatalf("got %d messages, want %d", len(messages), test.wantMessages)
+			}
+			if len(messages) > 0 && messages[0].Message.Data["id"] != "7" {
+				t.Errorf("data not kept, got %v", messages[0].Message.Data)
+			}
+
+			// a sent dispatch is not due anymore, a failed one waits for its backoff
+			_, err = service.ProcessDue(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(fake.Messages()) != test.wantMessages {
+				t.Errorf("dispatch sent again")
+			}
+		})
+	}
+}
+
+func TestProcessDueDropsInvalidTokens(t *testing.T) {
+	fake := NewFakeProvider()
+	fake.Invalid["token-gone"] = true
+	service, _ := newTestService(t, map[string]Provider{ProviderFCM: fake})
+	ctx := context.Background()
+
+	registerDevice(t, service, "token-gone", ProviderFCM)
+	_, _, err := service.Enqueue(ctx, "announcement:8", []string{TopicAnnouncements}, Message{Title: "Info"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.ProcessDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device, err := service.repository.FindByToken(ctx, "token-gone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.ID != 0 {
+		t.Error("invalid token was kept")
+	}
+}
+
+func TestFakeProviderKeepsLastMessages(t *testing.T) {
+	fake := NewFakeProvider()
+	for i := 0; i < maxFakeMessages+5; i++ {
+		_, _ = fake.Send(context.Background(), []string{"token"}, Message{Title: "x"})
+	}
+	if len(fake.Messages()) != maxFakeMessages {
+		t.Errorf("got %d messages, want %d", len(fake.Messages()), maxFakeMessages)
+	}
+}
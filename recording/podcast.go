@@ -0,0 +1,143 @@
+package recording
+
+import (
+	"encoding/xml"
+	"fmt"
+	"nurul-iman-blok-m/model"
+	"strconv"
+	"time"
+)
+
+// PodcastInfo describes the channel, the episodes come from the recordings
+type PodcastInfo struct {
+	Title       string
+	Link        string
+	Description string
+	Language    string
+	Author      string
+	OwnerEmail  string
+	ImageURL    string
+	Category    string
+}
+
+type podcastRSS struct {
+	XMLName   xml.Name       `xml:"rss"`
+	Version   string         `xml:"version,attr"`
+	ITunesNS  string         `xml:"xmlns:itunes,attr"`
+	ContentNS string         `xml:"xmlns:content,attr"`
+	Channel   podcastChannel `xml:"channel"`
+}
+
+type podcastChannel struct {
+	Title       string          `xml:"title"`
+	Link        string          `xml:"link"`
+	Description string          `xml:"description"`
+	Language    string          `xml:"language"`
+	LastBuild   string          `xml:"lastBuildDate,omitempty"`
+	Author      string          `xml:"itunes:author"`
+	Summary     string          `xml:"itunes:summary"`
+	Type        string          `xml:"itunes:type"`
+	Explicit    string          `xml:"itunes:explicit"`
+	Image       podcastImage    `xml:"itunes:image"`
+	Category    podcastCategory `xml:"itunes:category"`
+	Owner       podcastOwner    `xml:"itunes:owner"`
+	Items       []podcastItem   `xml:"item"`
+}
+
+type podcastImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type podcastCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type podcastOwner struct {
+	Name  string `xml:"itunes:name"`
+	Email string `xml:"itunes:email"`
+}
+
+type podcastItem struct {
+	Title       string           `xml:"title"`
+	Description string           `xml:"description"`
+	Enclosure   podcastEnclosure `xml:"enclosure"`
+	GUID        podcastGUID      `xml:"guid"`
+	PubDate     string           `xml:"pubDate"`
+	Author      string           `xml:"itunes:author"`
+	Duration    string           `xml:"itunes:duration"`
+	Explicit    string           `xml:"itunes:explicit"`
+	EpisodeType string           `xml:"itunes:episodeType"`
+}
+
+type podcastEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type podcastGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// PodcastFeed renders the recordings as an RSS 2.0 feed with the itunes tags podcast apps expect
+func PodcastFeed(info PodcastInfo, recordings []model.AudioRecording) ([]byte, error) {
+	channel := podcastChannel{
+		Title:       info.Title,
+		Link:        info.Link,
+		Description: info.Description,
+		Language:    info.Language,
+		Author:      info.Author,
+		Summary:     info.Description,
+		Type:        "episodic",
+		Explicit:    "false",
+		Image:       podcastImage{Href: info.ImageURL},
+		Category:    podcastCategory{Text: info.Category},
+		Owner:       podcastOwner{Name: info.Author, Email: info.OwnerEmail},
+		Items:       []podcastItem{},
+	}
+	if len(recordings) > 0 {
+		channel.LastBuild = recordings[0].RecordedAt.Format(time.RFC1123Z)
+	}
+
+	for _, recording := range recordings {
+		description := recording.Description
+		if description == "" {
+			description = recording.StudyRundown.Title
+		}
+
+		channel.Items = append(channel.Items, podcastItem{
+			Title:       recording.Title,
+			Description: description,
+			Enclosure: podcastEnclosure{
+				URL:    recording.URL,
+				Length: strconv.FormatInt(recording.Size, 10),
+				Type:   recording.ContentType,
+			},
+			GUID:        podcastGUID{IsPermaLink: "false", Value: fmt.Sprintf("recording-%d", recording.ID)},
+			PubDate:     recording.RecordedAt.Format(time.RFC1123Z),
+			Author:      recording.Speaker.Name,
+			Duration:    formatDuration(recording.Duration),
+			Explicit:    "false",
+			EpisodeType: "full",
+		})
+	}
+
+	feed := podcastRSS{
+		Version:   "2.0",
+		ITunesNS:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel:   channel,
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// formatDuration writes seconds as HH:MM:SS
+func formatDuration(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+}
@@ -0,0 +1,20 @@
+package recording
+
+type RecordingInput struct {
+	StudyRundownID uint   `json:"study_rundown_id" binding:"required"`
+	Title          string `json:"title" binding:"required"`
+	Description    string `json:"description"`
+	SpeakerID      uint   `json:"speaker_id"`
+	UploadKey      string `json:"upload_key" binding:"required"`
+	Duration       int    `json:"duration" binding:"required,gt=0"`
+	RecordedAt     string `json:"recorded_at" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type RecordingDetailInput struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type RecordingListInput struct {
+	StudyRundownID uint `form:"study_rundown_id"`
+	SpeakerID      uint `form:"speaker_id"`
+}
@@ -0,0 +1,48 @@
+package recording
+
+import (
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type RecordingFormatter struct {
+	ID             uint      `json:"id"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	StudyRundownID uint      `json:"study_rundown_id"`
+	StudyRundown   string    `json:"study_rundown"`
+	SpeakerID      uint      `json:"speaker_id"`
+	SpeakerName    string    `json:"speaker_name"`
+	URL            string    `json:"url"`
+	ContentType    string    `json:"content_type"`
+	Size           int64     `json:"size"`
+	Duration       int       `json:"duration"`
+	RecordedAt     time.Time `json:"recorded_at"`
+}
+
+func RecordingJsonFormatter(recording model.AudioRecording) RecordingFormatter {
+	return RecordingFormatter{
+		ID:             recording.ID,
+		Title:          recording.Title,
+		Description:    recording.Description,
+		StudyRundownID: recording.StudyRundownID,
+		StudyRundown:   recording.StudyRundown.Title,
+		SpeakerID:      recording.SpeakerID,
+		SpeakerName:    recording.Speaker.Name,
+		URL:            recording.URL,
+		ContentType:    recording.ContentType,
+		Size:           recording.Size,
+		Duration:       recording.Duration,
+		RecordedAt:     recording.RecordedAt,
+	}
+}
+
+func ListRecordingJsonFormatter(recordings []model.AudioRecording) []RecordingFormatter {
+	formatter := []RecordingFormatter{}
+
+	for _, recording := range recordings {
+		formatter = append(formatter, RecordingJsonFormatter(recording))
+	}
+
+	return formatter
+}
@@ -0,0 +1,92 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+)
+
+type RecordingRepository interface {
+	SaveRecording(ctx context.Context, recording model.AudioRecording) (model.AudioRecording, error)
+	FindByID(ctx context.Context, ID uint) (model.AudioRecording, error)
+	GetListRecording(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.AudioRecording, int, error)
+	GetLatest(ctx context.Context, limit int) ([]model.AudioRecording, error)
+	DeleteRecording(ctx context.Context, ID uint) error
+	FindRundown(ctx context.Context, ID uint) (model.StudyRundown, error)
+}
+
+type recordingRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *recordingRepository {
+	return &recordingRepository{db}
+}
+
+func (r *recordingRepository) SaveRecording(ctx context.Context, recording model.AudioRecording) (model.AudioRecording, error) {
+	err := r.db.WithContext(ctx).Omit("StudyRundown", "Speaker", "User").Save(&recording).Error
+	if err != nil {
+		return recording, err
+	}
+	return recording, nil
+}
+
+func (r *recordingRepository) FindByID(ctx context.Context, ID uint) (model.AudioRecording, error) {
+	var recording model.AudioRecording
+	err := r.db.WithContext(ctx).Preload("StudyRundown").Preload("Speaker").Where("id = ?", ID).First(&recording).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return recording, errNotFound
+	}
+	if err != nil {
+		return recording, err
+	}
+	return recording, nil
+}
+
+func (r *recordingRepository) GetListRecording(ctx context.Context, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.AudioRecording, int, error) {
+	var recordings []model.AudioRecording
+	err := r.db.WithContext(ctx).Preload("StudyRundown").Preload("Speaker").
+		Scopes(filter, list).
+		Order("recorded_at desc").
+		Find(&recordings).Error
+	if err != nil {
+		return recordings, 0, err
+	}
+
+	totalCount := int64(0)
+	r.db.WithContext(ctx).Model(&model.AudioRecording{}).Scopes(filter).Count(&totalCount)
+	return recordings, int(totalCount), nil
+}
+
+func (r *recordingRepository) GetLatest(ctx context.Context, limit int) ([]model.AudioRecording, error) {
+	var recordings []model.AudioRecording
+	err := r.db.WithContext(ctx).Preload("StudyRundown").Preload("Speaker").
+		Order("recorded_at desc").
+		Limit(limit).
+		Find(&recordings).Error
+	if err != nil {
+		return recordings, err
+	}
+	return recordings, nil
+}
+
+func (r *recordingRepository) DeleteRecording(ctx context.Context, ID uint) error {
+	err := r.db.WithContext(ctx).Delete(&model.AudioRecording{}, ID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *recordingRepository) FindRundown(ctx context.Context, ID uint) (model.StudyRundown, error) {
+	var rundown model.StudyRundown
+	err := r.db.WithContext(ctx).Where("id = ?", ID).First(&rundown).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rundown, errRundownNotFound
+	}
+	if err != nil {
+		return rundown, err
+	}
+	return rundown, nil
+}
@@ -0,0 +1,137 @@
+package recording
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/media"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/upload"
+	"strings"
+	"time"
+)
+
+// FeedSize is the number of episodes listed in the podcast feed
+const FeedSize = 100
+
+var (
+	errNotFound        = apperr.NotFound("recording_not_found", "recording not found")
+	errRundownNotFound = apperr.NotFound("rundown_not_found", "study rundown not found")
+	errNotAudio        = apperr.Validation("not_audio", "recording must be an audio file")
+)
+
+type RecordingService interface {
+	AddRecording(ctx context.Context, input RecordingInput, userID uint) (model.AudioRecording, error)
+	GetListRecording(ctx context.Context, input RecordingListInput, list func(db *gorm.DB) *gorm.DB) ([]model.AudioRecording, int, error)
+	GetDetailRecording(ctx context.Context, input RecordingDetailInput) (model.AudioRecording, error)
+	DeleteRecording(ctx context.Context, input RecordingDetailInput) error
+	GetFeedRecordings(ctx context.Context) ([]model.AudioRecording, error)
+}
+
+type recordingService struct {
+	repository    RecordingRepository
+	uploadService upload.UploadService
+	mediaService  media.MediaService
+}
+
+func NewService(repository RecordingRepository, uploadService upload.UploadService, mediaService media.MediaService) *recordingService {
+	return &recordingService{repository, uploadService, mediaService}
+}
+
+// AddRecording takes the key of a finished presigned upload, the file itself never passes through the api
+func (s *recordingService) AddRecording(ctx context.Context, input RecordingInput, userID uint) (model.AudioRecording, error) {
+	rundown, err := s.repository.FindRundown(ctx, input.StudyRundownID)
+	if err != nil {
+		return model.AudioRecording{}, err
+	}
+
+	recordedAt := time.Now()
+	if input.RecordedAt != "" {
+		recordedAt, err = time.Parse(time.RFC3339, input.RecordedAt)
+		if err != nil {
+			return model.AudioRecording{}, apperr.Validation("invalid_recorded_at", "recorded_at must be an RFC 3339 time")
+		}
+	}
+
+	// the ustadz of the rundown is the speaker unless another one is given
+	speakerID := rundown.UserID
+	if input.SpeakerID != 0 {
+		speakerID = input.SpeakerID
+	}
+
+	file, err := s.uploadService.Claim(ctx, input.UploadKey, userID)
+	if err != nil {
+		return model.AudioRecording{}, err
+	}
+	if !strings.HasPrefix(file.ContentType, "audio/") {
+		return model.AudioRecording{}, errNotAudio
+	}
+
+	recording := model.AudioRecording{}
+	recording.Title = input.Title
+	recording.Description = input.Description
+	recording.StudyRundownID = rundown.ID
+	recording.SpeakerID = speakerID
+	recording.URL = file.URL
+	recording.ContentType = file.ContentType
+	recording.Size = file.Size
+	recording.Duration = input.Duration
+	recording.RecordedAt = recordedAt
+	recording.UserID = userID
+
+	saved, err := s.repository.SaveRecording(ctx, recording)
+	if err != nil {
+		return saved, err
+	}
+
+	err = s.mediaService.SyncReferences(ctx, media.EntityRecording, saved.ID, media.FieldAudio, saved.URL)
+	if err != nil {
+		return saved, err
+	}
+
+	return s.repository.FindByID(ctx, saved.ID)
+}
+
+func (s *recordingService) GetListRecording(ctx context.Context, input RecordingListInput, list func(db *gorm.DB) *gorm.DB) ([]model.AudioRecording, int, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		if input.StudyRundownID != 0 {
+			db = db.Where("study_rundown_id = ?", input.StudyRundownID)
+		}
+		if input.SpeakerID != 0 {
+			db = db.Where("speaker_id = ?", input.SpeakerID)
+		}
+		return db
+	}
+
+	recordings, total, err := s.repository.GetListRecording(ctx, filter, list)
+	if err != nil {
+		return recordings, total, err
+	}
+	return recordings, total, nil
+}
+
+func (s *recordingService) GetDetailRecording(ctx context.Context, input RecordingDetailInput) (model.AudioRecording, error) {
+	recording, err := s.repository.FindByID(ctx, input.ID)
+	if err != nil {
+		return recording, err
+	}
+	return recording, nil
+}
+
+// DeleteRecording drops the reference to the audio file, the media garbage collector removes it from storage
+func (s *recordingService) DeleteRecording(ctx context.Context, input RecordingDetailInput) error {
+	err := s.repository.DeleteRecording(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.mediaService.SyncReferences(ctx, media.EntityRecording, input.ID, media.FieldAudio)
+}
+
+func (s *recordingService) GetFeedRecordings(ctx context.Context) ([]model.AudioRecording, error) {
+	recordings, err := s.repository.GetLatest(ctx, FeedSize)
+	if err != nil {
+		return recordings, err
+	}
+	return recordings, nil
+}
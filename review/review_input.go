@@ -0,0 +1,19 @@
+package review
+
+type ReviewSubmitInput struct {
+	ContentType string    `json:"content_type" binding:"required"`
+	ContentID   uint      `json:"content_id" binding:"required"`
+	Submitter   Submitter `json:"-"`
+}
+
+type ReviewDetailInput struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type ReviewDecisionInput struct {
+	Comment string `json:"comment"`
+}
+
+type ReviewRejectInput struct {
+	Comment string `json:"comment" binding:"required"`
+}
@@ -0,0 +1,44 @@
+package review
+
+import (
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type ReviewFormatter struct {
+	ID          uint       `json:"id"`
+	ContentType string     `json:"content_type"`
+	ContentID   uint       `json:"content_id"`
+	Title       string     `json:"title"`
+	Author      string     `json:"author"`
+	Status      string     `json:"status"`
+	Comment     string     `json:"comment"`
+	Reviewer    string     `json:"reviewer"`
+	DecidedAt   *time.Time `json:"decided_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func ReviewJsonFormatter(review model.Review) ReviewFormatter {
+	return ReviewFormatter{
+		ID:          review.ID,
+		ContentType: review.ContentType,
+		ContentID:   review.ContentID,
+		Title:       review.Title,
+		Author:      review.Author.Name,
+		Status:      review.Status,
+		Comment:     review.Comment,
+		Reviewer:    review.Reviewer.Name,
+		DecidedAt:   review.DecidedAt,
+		CreatedAt:   review.CreatedAt,
+	}
+}
+
+func ListReviewJsonFormatter(reviews []model.Review) []ReviewFormatter {
+	formatter := []ReviewFormatter{}
+
+	for _, review := range reviews {
+		formatter = append(formatter, ReviewJsonFormatter(review))
+	}
+
+	return formatter
+}
@@ -0,0 +1,26 @@
+package review
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"nurul-iman-blok-m/model"
+)
+
+// Notifier tells the author of a reviewed item about the decision. It runs inside the transaction
+// that stores the decision, tx is that transaction and an error rolls the decision back
+type Notifier interface {
+	NotifyReviewDecision(ctx context.Context, tx *gorm.DB, review model.Review) error
+}
+
+type logNotifier struct {
+}
+
+func NewLogNotifier() *logNotifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) NotifyReviewDecision(ctx context.Context, tx *gorm.DB, review model.Review) error {
+	log.Printf("review: %s %d %q was %s for author %d, comment: %q", review.ContentType, review.ContentID, review.Title, review.Status, review.AuthorID, review.Comment)
+	return nil
+}
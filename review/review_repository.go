@@ -0,0 +1,96 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nurul-iman-blok-m/model"
+)
+
+type ReviewRepository interface {
+	SaveReview(ctx context.Context, review model.Review, prepare func(tx *gorm.DB, review *model.Review) error) (model.Review, error)
+	SaveDecision(ctx context.Context, review model.Review, apply func(tx *gorm.DB, saved model.Review) error) (model.Review, error)
+	FindByID(ctx context.Context, ID uint) (model.Review, error)
+	FindPending(ctx context.Context, contentType string, contentID uint) (model.Review, error)
+	GetQueue(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.Review, int, error)
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *reviewRepository {
+	return &reviewRepository{db}
+}
+
+// SaveReview runs prepare and stores the review in one transaction, prepare moves the content
+// into review and fills in what the review shows about it
+func (r *reviewRepository) SaveReview(ctx context.Context, review model.Review, prepare func(tx *gorm.DB, review *model.Review) error) (model.Review, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		errPrepare := prepare(tx, &review)
+		if errPrepare != nil {
+			return errPrepare
+		}
+		return tx.Omit(clause.Associations).Save(&review).Error
+	})
+	if err != nil {
+		return review, err
+	}
+	return review, nil
+}
+
+// SaveDecision stores the decided review and runs apply with it in the same transaction,
+// so the content state and the notification commit exactly when the decision does
+func (r *reviewRepository) SaveDecision(ctx context.Context, review model.Review, apply func(tx *gorm.DB, saved model.Review) error) (model.Review, error) {
+	var saved model.Review
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		errSave := tx.Omit(clause.Associations).Save(&review).Error
+		if errSave != nil {
+			return errSave
+		}
+
+		errFind := tx.Preload("Author").Preload("Reviewer").Where("id = ?", review.ID).First(&saved).Error
+		if errFind != nil {
+			return errFind
+		}
+		return apply(tx, saved)
+	})
+	if err != nil {
+		return review, err
+	}
+	return saved, nil
+}
+
+func (r *reviewRepository) FindByID(ctx context.Context, ID uint) (model.Review, error) {
+	var review model.Review
+	err := r.db.WithContext(ctx).Preload("Author").Preload("Reviewer").Where("id = ?", ID).First(&review).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return review, errNotFound
+	}
+	if err != nil {
+		return review, err
+	}
+	return review, nil
+}
+
+func (r *reviewRepository) FindPending(ctx context.Context, contentType string, contentID uint) (model.Review, error) {
+	var review model.Review
+	err := r.db.WithContext(ctx).Where("content_type = ? AND content_id = ? AND status = ?", contentType, contentID, StatusPending).Find(&review).Error
+	if err != nil {
+		return review, err
+	}
+	return review, nil
+}
+
+func (r *reviewRepository) GetQueue(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.Review, int, error) {
+	var reviews []model.Review
+	err := r.db.WithContext(ctx).Preload("Author").Where("status = ?", StatusPending).Scopes(list).Order("created_at asc").Find(&reviews).Error
+	if err != nil {
+		return reviews, 0, err
+	}
+
+	totalCount := int64(0)
+	r.db.WithContext(ctx).Model(&model.Review{}).Where("status = ?", StatusPending).Count(&totalCount)
+	return reviews, int(totalCount), nil
+}
@@ -0,0 +1,148 @@
+package review
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+
+	ContentAnnouncement = "announcement"
+	ContentArticle      = "article"
+)
+
+var (
+	errNotFound      = apperr.NotFound("review_not_found", "review not found")
+	errNotReviewable = apperr.Validation("content_not_reviewable", "content type can not be reviewed")
+)
+
+// ReviewContent is what a content service reports back when an item is submitted
+type ReviewContent struct {
+	Title    string
+	AuthorID uint
+}
+
+// Submitter is who asks for the review, content services only accept the author or a publisher
+type Submitter struct {
+	UserID     uint
+	CanPublish bool
+}
+
+// Reviewable is implemented by every content service that goes through editorial approval.
+// Submit, approve and reject change the content through tx, which also saves the review row,
+// so both commit or neither does. ReviewDecided runs after the commit, for notifications
+type Reviewable interface {
+	SubmitForReview(ctx context.Context, tx *gorm.DB, ID uint, submitter Submitter) (ReviewContent, error)
+	ApproveReview(ctx context.Context, tx *gorm.DB, ID uint) error
+	RejectReview(ctx context.Context, tx *gorm.DB, ID uint) error
+	ReviewDecided(ctx context.Context, ID uint, status string)
+}
+
+type ReviewService interface {
+	Submit(ctx context.Context, input ReviewSubmitInput) (model.Review, error)
+	Approve(ctx context.Context, input ReviewDetailInput, reviewerID uint, comment string) (model.Review, error)
+	Reject(ctx context.Context, input ReviewDetailInput, reviewerID uint, comment string) (model.Review, error)
+	GetQueue(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.Review, int, error)
+}
+
+type reviewService struct {
+	repository  ReviewRepository
+	notifier    Notifier
+	reviewables map[string]Reviewable
+}
+
+func NewService(repository ReviewRepository, notifier Notifier, reviewables map[string]Reviewable) *reviewService {
+	return &reviewService{repository, notifier, reviewables}
+}
+
+func (s *reviewService) Submit(ctx context.Context, input ReviewSubmitInput) (model.Review, error) {
+	reviewable, ok := s.reviewables[input.ContentType]
+	if !ok {
+		return model.Review{}, errNotReviewable
+	}
+
+	pending, err := s.repository.FindPending(ctx, input.ContentType, input.ContentID)
+	if err != nil {
+		return pending, err
+	}
+	if pending.ID != 0 {
+		return pending, apperr.Conflict("review_pending", "content is already waiting for review")
+	}
+
+	review := model.Review{}
+	review.ContentType = input.ContentType
+	review.ContentID = input.ContentID
+	review.Status = StatusPending
+
+	return s.repository.SaveReview(ctx, review, func(tx *gorm.DB, review *model.Review) error {
+		content, errSubmit := reviewable.SubmitForReview(ctx, tx, input.ContentID, input.Submitter)
+		if errSubmit != nil {
+			return errSubmit
+		}
+		review.Title = content.Title
+		review.AuthorID = content.AuthorID
+		return nil
+	})
+}
+
+func (s *reviewService) Approve(ctx context.Context, input ReviewDetailInput, reviewerID uint, comment string) (model.Review, error) {
+	return s.decide(ctx, input, reviewerID, comment, StatusApproved)
+}
+
+func (s *reviewService) Reject(ctx context.Context, input ReviewDetailInput, reviewerID uint, comment string) (model.Review, error) {
+	return s.decide(ctx, input, reviewerID, comment, StatusRejected)
+}
+
+func (s *reviewService) GetQueue(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.Review, int, error) {
+	reviews, count, err := s.repository.GetQueue(ctx, list)
+	if err != nil {
+		return reviews, 0, err
+	}
+	return reviews, count, nil
+}
+
+func (s *reviewService) decide(ctx context.Context, input ReviewDetailInput, reviewerID uint, comment string, status string) (model.Review, error) {
+	review, err := s.repository.FindByID(ctx, input.ID)
+	if err != nil {
+		return review, err
+	}
+	if review.Status != StatusPending {
+		return review, apperr.Conflict("review_decided", "review is already decided")
+	}
+
+	reviewable, ok := s.reviewables[review.ContentType]
+	if !ok {
+		return review, errNotReviewable
+	}
+
+	now := time.Now()
+	review.Status = status
+	review.Comment = comment
+	review.ReviewerID = &reviewerID
+	review.DecidedAt = &now
+
+	decided, err := s.repository.SaveDecision(ctx, review, func(tx *gorm.DB, saved model.Review) error {
+		var errDecide error
+		if status == StatusApproved {
+			errDecide = reviewable.ApproveReview(ctx, tx, saved.ContentID)
+		} else {
+			errDecide = reviewable.RejectReview(ctx, tx, saved.ContentID)
+		}
+		if errDecide != nil {
+			return errDecide
+		}
+		return s.notifier.NotifyReviewDecision(ctx, tx, saved)
+	})
+	if err != nil {
+		return decided, err
+	}
+
+	reviewable.ReviewDecided(ctx, decided.ContentID, decided.Status)
+	return decided, nil
+}
@@ -0,0 +1,31 @@
+package role
+
+const (
+	// PermissionEditContent sees drafts and scheduled content and edits it, publishing needs PermissionPublish
+	PermissionEditContent   = "content.edit"
+	PermissionPublish       = "content.publish"
+	PermissionSubmitReview  = "review.submit"
+	PermissionApproveReview = "review.approve"
+	PermissionManageWebhook = "webhook.manage"
+	PermissionBroadcast     = "broadcast.send"
+)
+
+// Names are the roles the api knows, seed creates them on a fresh database
+var Names = []string{"super-admin", "takmir", "admin", "ustadz", "user"}
+
+// takmir is the mosque board, the chair approves content before it goes public
+var rolePermissions = map[string][]string{
+	"super-admin": {PermissionEditContent, PermissionPublish, PermissionSubmitReview, PermissionApproveReview, PermissionManageWebhook, PermissionBroadcast},
+	"takmir":      {PermissionEditContent, PermissionPublish, PermissionSubmitReview, PermissionApproveReview, PermissionManageWebhook, PermissionBroadcast},
+	"admin":       {PermissionEditContent, PermissionSubmitReview, PermissionBroadcast},
+	"ustadz":      {PermissionSubmitReview},
+}
+
+func Can(roleName string, permission string) bool {
+	for _, item := range rolePermissions[roleName] {
+		if item == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,16 @@
 package role
 
 import (
+	"context"
 	"gorm.io/gorm"
+	"nurul-iman-blok-m/database"
 	"nurul-iman-blok-m/model"
 )
 
 type RoleRepository interface {
-	SaveRole(input model.Role) (model.Role, error)
-	GetAllRole() ([]model.Role, error)
-	SearchRole(search string) ([]model.Role, error)
+	SaveRole(ctx context.Context, input model.Role) (model.Role, error)
+	GetAllRole(ctx context.Context) ([]model.Role, error)
+	SearchRole(ctx context.Context, search string) ([]model.Role, error)
 }
 
 type roleRepository struct {
@@ -19,8 +21,8 @@ func NewRepository(db *gorm.DB) *roleRepository {
 	return &roleRepository{db: db}
 }
 
-func (r *roleRepository) SaveRole(role model.Role) (model.Role, error) {
-	err := r.db.Save(&role).Error
+func (r *roleRepository) SaveRole(ctx context.Context, role model.Role) (model.Role, error) {
+	err := r.db.WithContext(ctx).Save(&role).Error
 
 	if err != nil {
 		return role, err
@@ -29,9 +31,9 @@ func (r *roleRepository) SaveRole(role model.Role) (model.Role, error) {
 	return role, nil
 }
 
-func (r *roleRepository) GetAllRole() ([]model.Role, error) {
+func (r *roleRepository) GetAllRole(ctx context.Context) ([]model.Role, error) {
 	var role []model.Role
-	err := r.db.Find(&role).Error
+	err := r.db.WithContext(ctx).Find(&role).Error
 	if err != nil {
 		return role, err
 	}
@@ -39,9 +41,9 @@ func (r *roleRepository) GetAllRole() ([]model.Role, error) {
 	return role, nil
 }
 
-func (r *roleRepository) SearchRole(search string) ([]model.Role, error) {
+func (r *roleRepository) SearchRole(ctx context.Context, search string) ([]model.Role, error) {
 	var role []model.Role
-	err := r.db.Where("role_name like ?", "%"+search+"%").Find(&role).Error
+	err := r.db.WithContext(ctx).Scopes(database.Contains("role_name", search)).Find(&role).Error
 	if err != nil {
 		return role, err
 	}
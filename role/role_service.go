@@ -1,10 +1,13 @@
 package role
 
-import "nurul-iman-blok-m/model"
+import (
+	"context"
+	"nurul-iman-blok-m/model"
+)
 
 type RoleService interface {
-	SaveRole(input RoleInput) (model.Role, error)
-	GetRoles(search string) ([]model.Role, error)
+	SaveRole(ctx context.Context, input RoleInput) (model.Role, error)
+	GetRoles(ctx context.Context, search string) ([]model.Role, error)
 }
 
 type roleService struct {
@@ -15,26 +18,26 @@ func NewRoleService(repository RoleRepository) *roleService {
 	return &roleService{repository}
 }
 
-func (s *roleService) SaveRole(input RoleInput) (model.Role, error) {
+func (s *roleService) SaveRole(ctx context.Context, input RoleInput) (model.Role, error) {
 	role := model.Role{}
 	role.RoleName = input.RoleName
-	newRole, err := s.repository.SaveRole(role)
+	newRole, err := s.repository.SaveRole(ctx, role)
 	if err != nil {
 		return role, err
 	}
 	return newRole, nil
 }
 
-func (s *roleService) GetRoles(search string) ([]model.Role, error) {
+func (s *roleService) GetRoles(ctx context.Context, search string) ([]model.Role, error) {
 	if search != "" {
-		roles, err := s.repository.SearchRole(search)
+		roles, err := s.repository.SearchRole(ctx, search)
 		if err != nil {
 			return roles, err
 		}
 		return roles, nil
 	}
 
-	roles, err := s.repository.GetAllRole()
+	roles, err := s.repository.GetAllRole(ctx)
 	if err != nil {
 		return roles, err
 	}
@@ -0,0 +1,52 @@
+package seed
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+)
+
+// CreateAdmin makes the user with email a super-admin. A new account gets a random password,
+// which is returned once; an existing account keeps its password and password is empty
+func CreateAdmin(db *gorm.DB, email string, name string) (model.User, string, error) {
+	var adminRole model.Role
+	err := db.Where("role_name = ?", "super-admin").First(&adminRole).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.User{}, "", errors.New("role super-admin is missing, run seed first")
+	}
+	if err != nil {
+		return model.User{}, "", err
+	}
+
+	var user model.User
+	err = db.Where("email = ?", email).First(&user).Error
+	if err == nil {
+		user.RoleID = adminRole.ID
+		return user, "", db.Model(&user).Update("role_id", adminRole.ID).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, "", err
+	}
+
+	random := make([]byte, 12)
+	_, err = rand.Read(random)
+	if err != nil {
+		return user, "", err
+	}
+	password := base64.RawURLEncoding.EncodeToString(random)
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return user, "", err
+	}
+
+	user = model.User{Name: name, Email: email, Password: string(passwordHash), RoleID: adminRole.ID}
+	err = db.Omit("Role").Create(&user).Error
+	if err != nil {
+		return user, "", err
+	}
+	return user, password, nil
+}
@@ -0,0 +1,128 @@
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/role"
+	"time"
+)
+
+// DemoPassword is the password of every demo account, never seed demo content in production
+const DemoPassword = "demo12345"
+
+// Roles creates the roles of role.Names that are missing and returns how many were added
+func Roles(db *gorm.DB) (int, error) {
+	created := 0
+	for _, name := range role.Names {
+		result := db.Where(model.Role{RoleName: name}).FirstOrCreate(&model.Role{RoleName: name})
+		if result.Error != nil {
+			return created, result.Error
+		}
+		created += int(result.RowsAffected)
+	}
+	return created, nil
+}
+
+// Demo adds sample accounts, announcements, kajian and an article so a fresh install has
+// something to show. It does nothing when the demo accounts already exist
+func Demo(db *gorm.DB) (bool, error) {
+	var existing int64
+	err := db.Model(&model.User{}).Where("email = ?", "takmir@demo.local").Count(&existing).Error
+	if err != nil || existing > 0 {
+		return false, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		takmir, err := demoUser(tx, "Takmir Demo", "takmir@demo.local", "takmir")
+		if err != nil {
+			return err
+		}
+		ustadz, err := demoUser(tx, "Ustadz Ahmad Demo", "ustadz@demo.local", "ustadz")
+		if err != nil {
+			return err
+		}
+		_, err = demoUser(tx, "Jamaah Demo", "jamaah@demo.local", "user")
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		announcements := []model.Announcement{
+			{
+				Title:       "Kerja Bakti Bersih Masjid",
+				Description: "Mari bersama membersihkan masjid pada hari Ahad pagi pukul 07.00. Peralatan kebersihan disediakan panitia.",
+				Slug:        "demo-kerja-bakti-bersih-masjid",
+				Status:      "published",
+				UserID:      takmir.ID,
+			},
+			{
+				Title:       "Penerimaan Zakat Fitrah",
+				Description: "Panitia zakat menerima zakat fitrah setiap hari setelah shalat Isya di serambi masjid.",
+				Slug:        "demo-penerimaan-zakat-fitrah",
+				Status:      "published",
+				UserID:      takmir.ID,
+			},
+		}
+		for _, announcement := range announcements {
+			err = tx.Omit("User").Create(&announcement).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, title := range []string{"Tafsir Al-Qur'an", "Fiqih Ibadah", "Sirah Nabawiyah"} {
+			rundown := model.StudyRundown{
+				Title:        title,
+				OnScheduled:  true,
+				ScheduleDate: now.AddDate(0, 0, 2*i+1).Format("2006-01-02"),
+				Time:         "18:30",
+				UserID:       ustadz.ID,
+			}
+			err = tx.Omit("User").Create(&rundown).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		category := model.Category{CategoryName: "Kajian"}
+		err = tx.Create(&category).Error
+		if err != nil {
+			return err
+		}
+		article := model.Article{
+			Title:       "Keutamaan Shalat Berjamaah",
+			Description: "Shalat berjamaah lebih utama dua puluh tujuh derajat dibanding shalat sendirian.",
+			Slug:        "demo-keutamaan-shalat-berjamaah",
+			UserID:      ustadz.ID,
+			CategoryID:  category.ID,
+		}
+		return tx.Omit("User", "Category").Create(&article).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func demoUser(tx *gorm.DB, name string, email string, roleName string) (model.User, error) {
+	var userRole model.Role
+	err := tx.Where("role_name = ?", roleName).First(&userRole).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.User{}, fmt.Errorf("role %s is missing, seed the roles first", roleName)
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(DemoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user := model.User{Name: name, Email: email, Password: string(passwordHash), RoleID: userRole.ID}
+	err = tx.Omit("Role").Create(&user).Error
+	return user, err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"nurul-iman-blok-m/config"
+	"nurul-iman-blok-m/database"
+	"nurul-iman-blok-m/worker"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// runServer serves until SIGTERM or SIGINT, then stops accepting connections, lets in-flight requests
+// and running background jobs finish within ShutdownTimeout, flushes pending spans and closes the database
+func runServer(router *gin.Engine, port int, cfg config.ServerConfig, jobs *worker.Group, db *gorm.DB, flushTracing func(ctx context.Context) error) {
+	server := &http.Server{
+		Addr:              ":" + strconv.Itoa(port),
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Duration(cfg.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.IdleTimeout) * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	failed := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", server.Addr)
+		err := server.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+
+	select {
+	case err := <-failed:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	stop()
+	log.Println("shutting down, draining requests")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Printf("shutdown: %v", err)
+	}
+	err = jobs.Stop(shutdownCtx)
+	if err != nil {
+		log.Printf("background jobs did not stop in time: %v", err)
+	}
+	err = flushTracing(shutdownCtx)
+	if err != nil {
+		log.Printf("flushing traces: %v", err)
+	}
+	err = database.Close(db)
+	if err != nil {
+		log.Printf("closing database: %v", err)
+	}
+	log.Println("stopped")
+}
@@ -0,0 +1,53 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// characters that do not decompose into a base letter plus a combining mark
+var transliterations = strings.NewReplacer(
+	"ß", "ss",
+	"æ", "ae",
+	"œ", "oe",
+	"ø", "o",
+	"đ", "d",
+	"ð", "d",
+	"ł", "l",
+	"þ", "th",
+	"&", " dan ",
+)
+
+// Make turns free text into a lowercase ascii slug, e.g. "Kajian Ba'da Maghrib!" -> "kajian-bada-maghrib"
+func Make(text string) string {
+	text = transliterations.Replace(strings.ToLower(text))
+
+	stripMarks := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if normalized, _, err := transform.String(stripMarks, text); err == nil {
+		text = normalized
+	}
+
+	var builder strings.Builder
+	separator := false
+	for _, char := range text {
+		switch {
+		case char == '\'' || char == '’' || char == '`':
+			// apostrophes join words instead of splitting them
+			continue
+		case char <= unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char)):
+			if separator && builder.Len() > 0 {
+				builder.WriteRune('-')
+			}
+			builder.WriteRune(char)
+			separator = false
+		default:
+			separator = true
+		}
+	}
+
+	return builder.String()
+}
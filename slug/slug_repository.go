@@ -0,0 +1,30 @@
+package slug
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type SlugRepository interface {
+	FindSlugs(ctx context.Context, table string, base string, ignoreID uint) ([]string, error)
+}
+
+type slugRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *slugRepository {
+	return &slugRepository{db}
+}
+
+func (r *slugRepository) FindSlugs(ctx context.Context, table string, base string, ignoreID uint) ([]string, error) {
+	var slugs []string
+	err := r.db.WithContext(ctx).Table(table).
+		Where("(slug = ? OR slug LIKE ?) AND id <> ?", base, base+"-%", ignoreID).
+		Pluck("slug", &slugs).Error
+	if err != nil {
+		return slugs, err
+	}
+
+	return slugs, nil
+}
@@ -0,0 +1,74 @@
+package slug
+
+import (
+	"context"
+	"nurul-iman-blok-m/database"
+	"strconv"
+	"strings"
+)
+
+const (
+	TableAnnouncements = "announcements"
+	TableArticles      = "articles"
+	TableStudyVideos   = "study_videos"
+
+	maxSaveAttempts = 5
+)
+
+type SlugService interface {
+	GenerateSlug(ctx context.Context, table string, text string, ignoreID uint) (string, error)
+	SaveWithSlug(ctx context.Context, table string, text string, ignoreID uint, save func(slug string) error) error
+}
+
+type slugService struct {
+	repository SlugRepository
+}
+
+func NewService(repository SlugRepository) *slugService {
+	return &slugService{repository}
+}
+
+// GenerateSlug returns a slug for text that is unique in table, appending -2, -3, ... on collision.
+// ignoreID lets an updated row keep its own slug, pass 0 for new rows.
+func (s *slugService) GenerateSlug(ctx context.Context, table string, text string, ignoreID uint) (string, error) {
+	base := Make(text)
+	if base == "" {
+		base = strings.TrimSuffix(table, "s")
+	}
+
+	existing, err := s.repository.FindSlugs(ctx, table, base, ignoreID)
+	if err != nil {
+		return "", err
+	}
+
+	taken := map[string]bool{}
+	for _, item := range existing {
+		taken[item] = true
+	}
+	if !taken[base] {
+		return base, nil
+	}
+
+	for suffix := 2; ; suffix++ {
+		candidate := base + "-" + strconv.Itoa(suffix)
+		if !taken[candidate] {
+			return candidate, nil
+		}
+	}
+}
+
+// SaveWithSlug passes a generated slug to save. The slug column has a unique index, when a concurrent
+// save took the slug first it generates the next free one and saves again
+func (s *slugService) SaveWithSlug(ctx context.Context, table string, text string, ignoreID uint, save func(slug string) error) error {
+	for attempt := 1; ; attempt++ {
+		generated, err := s.GenerateSlug(ctx, table, text, ignoreID)
+		if err != nil {
+			return err
+		}
+
+		err = save(generated)
+		if err == nil || attempt == maxSaveAttempts || !database.IsDuplicateKey(err) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package slug
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := map[string]string{
+		"Kajian Ahad Pagi":       "kajian-ahad-pagi",
+		"Kajian Ba'da Maghrib!":  "kajian-bada-maghrib",
+		"Café Crème":             "cafe-creme",
+		"Straße Łódź":            "strasse-lodz",
+		"Zakat & Infaq":          "zakat-dan-infaq",
+		"  Idul -- Fitri  1447 ": "idul-fitri-1447",
+		"كاجيان":                 "",
+		"":                       "",
+	}
+
+	for text, want := range tests {
+		if got := Make(text); got != want {
+			t.Errorf("Make(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+type fakeSlugRepository struct {
+	existing []string
+}
+
+func (r *fakeSlugRepository) FindSlugs(ctx context.Context, table string, base string, ignoreID uint) ([]string, error) {
+	return r.existing, nil
+}
+
+func TestGenerateSlug(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    string
+		text     string
+		existing []string
+		want     string
+	}{
+		{"free slug", TableAnnouncements, "Kajian Ahad", nil, "kajian-ahad"},
+		{"collision gets a suffix", TableAnnouncements, "Kajian Ahad", []string{"kajian-ahad"}, "kajian-ahad-2"},
+		{"next free suffix", TableAnnouncements, "Kajian Ahad", []string{"kajian-ahad", "kajian-ahad-2", "kajian-ahad-3"}, "kajian-ahad-4"},
+		{"gap in suffixes is reused", TableAnnouncements, "Kajian Ahad", []string{"kajian-ahad", "kajian-ahad-3"}, "kajian-ahad-2"},
+		{"only a suffixed row", TableAnnouncements, "Kajian Ahad", []string{"kajian-ahad-2"}, "kajian-ahad"},
+		{"empty text falls back to the table", TableStudyVideos, "!!!", nil, "study_video"},
+		{"fallback collides too", TableArticles, "", []string{"article"}, "article-2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewService(&fakeSlugRepository{existing: test.existing})
+			got, err := service.GenerateSlug(context.Background(), test.table, test.text, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSaveWithSlug(t *testing.T) {
+	repository := &fakeSlugRepository{}
+	service := NewService(repository)
+
+	// the first two slugs were taken by concurrent saves after GenerateSlug looked them up
+	var attempts []string
+	err := service.SaveWithSlug(context.Background(), TableAnnouncements, "Kajian Ahad", 0, func(slug string) error {
+		attempts = append(attempts, slug)
+		if len(attempts) < 3 {
+			repository.existing = append(repository.existing, slug)
+			return gorm.ErrDuplicatedKey
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"kajian-ahad", "kajian-ahad-2", "kajian-ahad-3"}
+	if strings.Join(attempts, ",") != strings.Join(want, ",") {
+		t.Errorf("tried %v, want %v", attempts, want)
+	}
+
+	errSave := errors.New("connection lost")
+	err = service.SaveWithSlug(context.Background(), TableAnnouncements, "Kajian Ahad", 0, func(slug string) error {
+		return errSave
+	})
+	if !errors.Is(err, errSave) {
+		t.Errorf("got %v, want the save error without a retry", err)
+	}
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"nurul-iman-blok-m/apperr"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage stands in for S3 during development. Presigned urls point back at the api,
+// which checks the signature and writes the body under dir.
+type LocalStorage struct {
+	dir       string
+	publicURL string
+	uploadURL string
+	secret    []byte
+}
+
+func NewLocalStorage(dir string, publicURL string, uploadURL string, secret string) *LocalStorage {
+	return &LocalStorage{dir, strings.TrimSuffix(publicURL, "/"), uploadURL, []byte(secret)}
+}
+
+func (s *LocalStorage) PresignPut(ctx context.Context, key string, contentType string, expires time.Duration) (PresignedRequest, error) {
+	expiresAt := time.Now().Add(expires)
+	expiresUnix := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("key", key)
+	query.Set("expires", expiresUnix)
+	query.Set("signature", s.sign(key, contentType, expiresUnix))
+
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+
+	return PresignedRequest{
+		URL:     s.uploadURL + "?" + query.Encode(),
+		Method:  http.MethodPut,
+		Headers: headers,
+		Expires: expiresAt,
+	}, nil
+}
+
+// Receive stores the body of a signed PUT request, maxSize guards the disk like S3 would guard the bucket
+func (s *LocalStorage) Receive(key string, contentType string, expires string, signature string, body io.Reader, maxSize int64) error {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, contentType, expires))) {
+		return apperr.Forbidden("invalid signature")
+	}
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresUnix {
+		return apperr.Forbidden("upload url expired")
+	}
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	written, err := io.Copy(file, io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if written > maxSize {
+		os.Remove(path)
+		return apperr.Validation("file_too_large", "file too large")
+	}
+
+	return os.WriteFile(path+".type", []byte(contentType), 0o644)
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, contentType string, body []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, body, 0o644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+".type", []byte(contentType), 0o644)
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	contentType, _ := os.ReadFile(path + ".type")
+	return ObjectInfo{Key: key, Size: info.Size(), ContentType: string(contentType)}, nil
+}
+
+func (s *LocalStorage) ReadStart(ctx context.Context, key string, size int64) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, size))
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	os.Remove(path + ".type")
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// Ping makes sure the upload folder exists and is a folder, it is created on the first start
+func (s *LocalStorage) Ping(ctx context.Context) error {
+	return os.MkdirAll(s.dir, 0o755)
+}
+
+func (s *LocalStorage) sign(key string, contentType string, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "|" + contentType + "|" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path keeps keys inside dir, a key like ../../etc/passwd is rejected
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", errors.New("invalid key")
+	}
+	return filepath.Join(s.dir, cleaned), nil
+}
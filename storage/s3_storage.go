@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
+	"nurul-iman-blok-m/config"
+	"nurul-iman-blok-m/tracing"
+	"strings"
+	"time"
+)
+
+// NewS3Client uses the configured keys, or the default credential chain when none are set
+func NewS3Client(ctx context.Context, cfg config.AWSConfig) (*s3.Client, error) {
+	options := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.Region)}
+	if cfg.AccessKeyID != "" {
+		creds := credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+		options = append(options, awsconfig.WithCredentialsProvider(creds))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	awsCfg.APIOptions = append(awsCfg.APIOptions, traceOperations(cfg.Bucket))
+	return s3.NewFromConfig(awsCfg), nil
+}
+
+// traceOperations opens a span around every S3 call, uploads through the transfer manager included.
+// It runs after the SDK registered the operation name, and cancelling ctx aborts the call
+func traceOperations(bucket string) func(stack *middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TraceOperation", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			ctx, span := tracing.Start(ctx, "s3."+awsmiddleware.GetOperationName(ctx),
+				attribute.String("rpc.system", "aws-api"),
+				attribute.String("rpc.service", awsmiddleware.GetServiceID(ctx)),
+				attribute.String("aws.s3.bucket", bucket),
+			)
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			tracing.End(span, err)
+			return out, metadata, err
+		}), middleware.After)
+	}
+}
+
+type s3Storage struct {
+	client    *s3.Client
+	presign   *s3.PresignClient
+	bucket    string
+	publicURL string
+}
+
+func NewS3Storage(client *s3.Client, bucket string, publicURL string) *s3Storage {
+	return &s3Storage{client, s3.NewPresignClient(client), bucket, strings.TrimSuffix(publicURL, "/")}
+}
+
+func (s *s3Storage) PresignPut(ctx context.Context, key string, contentType string, expires time.Duration) (PresignedRequest, error) {
+	request, err := s.presign.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		ACL:         "public-read",
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return PresignedRequest{}, err
+	}
+
+	return PresignedRequest{
+		URL:     request.URL,
+		Method:  request.Method,
+		Headers: request.SignedHeader,
+		Expires: time.Now().Add(expires),
+	}, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, contentType string, body []byte) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+		ACL:         "public-read",
+	})
+	return err
+}
+
+func (s *s3Storage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return ObjectInfo{}, ErrNotFound
+		}
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{
+		Key:         key,
+		Size:        head.ContentLength,
+		ContentType: aws.ToString(head.ContentType),
+	}, nil
+}
+
+func (s *s3Storage) ReadStart(ctx context.Context, key string, size int64) ([]byte, error) {
+	object, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", size-1)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	return io.ReadAll(io.LimitReader(object.Body, size))
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *s3Storage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *s3Storage) Ping(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.bucket)})
+	return err
+}
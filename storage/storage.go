@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	PrefixAnnouncements = "announcements"
+	PrefixArticles      = "articles"
+	PrefixVideos        = "videos"
+	PrefixRundowns      = "rundowns"
+	PrefixRecordings    = "recordings"
+	PrefixUploads       = "uploads"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// PresignedRequest is everything a client needs to upload straight to storage
+type PresignedRequest struct {
+	URL     string
+	Method  string
+	Headers http.Header
+	Expires time.Time
+}
+
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Storage hides where uploaded objects live, S3 in production and a local folder for development
+type Storage interface {
+	PresignPut(ctx context.Context, key string, contentType string, expires time.Duration) (PresignedRequest, error)
+	// Put stores a file the api produced itself, e.g. resized banners
+	Put(ctx context.Context, key string, contentType string, body []byte) error
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// ReadStart returns up to size bytes from the start of the object, enough to sniff its real type
+	ReadStart(ctx context.Context, key string, size int64) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+	// Ping reports whether the storage can be reached, for the readiness probe
+	Ping(ctx context.Context) error
+}
+
+// ContentKey derives the object key from the file content, the same upload always lands on the same key
+// e.g. announcements/3f1a...c9-large.jpg
+func ContentKey(prefix string, content []byte, variant string, extension string) string {
+	sum := sha256.Sum256(content)
+	name := hex.EncodeToString(sum[:16])
+	if variant != "" {
+		name += "-" + variant
+	}
+	return prefix + "/" + name + "." + extension
+}
+
+// RandomKey is used when the content is not known yet, e.g. for presigned uploads
+func RandomKey(prefix string, extension string) string {
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return prefix + "/" + hex.EncodeToString(random) + "." + extension
+}
+
+// KeyFromURL reverses Storage.URL, ok is false for urls that are not under publicURL
+func KeyFromURL(publicURL string, url string) (key string, ok bool) {
+	prefix := strings.TrimSuffix(publicURL, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, prefix), true
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"nurul-iman-blok-m/tracing"
+	"time"
+)
+
+type tracedStorage struct {
+	next Storage
+}
+
+// Traced wraps a Storage so every operation shows up as a span of the request that caused it
+func Traced(next Storage) Storage {
+	return &tracedStorage{next}
+}
+
+func (s *tracedStorage) PresignPut(ctx context.Context, key string, contentType string, expires time.Duration) (PresignedRequest, error) {
+	ctx, span := tracing.Start(ctx, "storage.presign_put", attribute.String("storage.key", key))
+	request, err := s.next.PresignPut(ctx, key, contentType, expires)
+	tracing.End(span, err)
+	return request, err
+}
+
+func (s *tracedStorage) Put(ctx context.Context, key string, contentType string, body []byte) error {
+	ctx, span := tracing.Start(ctx, "storage.put", attribute.String("storage.key", key), attribute.Int("storage.size", len(body)))
+	err := s.next.Put(ctx, key, contentType, body)
+	tracing.End(span, err)
+	return err
+}
+
+func (s *tracedStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	ctx, span := tracing.Start(ctx, "storage.stat", attribute.String("storage.key", key))
+	info, err := s.next.Stat(ctx, key)
+	if err == ErrNotFound {
+		// an upload that has not arrived yet is an answer, not a failure
+		span.SetAttributes(attribute.Bool("storage.found", false))
+		tracing.End(span, nil)
+		return info, err
+	}
+	span.SetAttributes(attribute.Int64("storage.size", info.Size))
+	tracing.End(span, err)
+	return info, err
+}
+
+func (s *tracedStorage) ReadStart(ctx context.Context, key string, size int64) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "storage.read_start", attribute.String("storage.key", key))
+	head, err := s.next.ReadStart(ctx, key, size)
+	tracing.End(span, err)
+	return head, err
+}
+
+func (s *tracedStorage) Delete(ctx context.Context, key string) error {
+	ctx, span := tracing.Start(ctx, "storage.delete", attribute.String("storage.key", key))
+	err := s.next.Delete(ctx, key)
+	tracing.End(span, err)
+	return err
+}
+
+func (s *tracedStorage) URL(key string) string {
+	return s.next.URL(key)
+}
+
+func (s *tracedStorage) Ping(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "storage.ping")
+	err := s.next.Ping(ctx)
+	tracing.End(span, err)
+	return err
+}
@@ -1,17 +1,19 @@
 package study_rundown
 
 import (
+	"context"
+	"errors"
 	"gorm.io/gorm"
 	"nurul-iman-blok-m/model"
 )
 
 type StudyRepository interface {
-	AddStudy(study model.StudyRundown) (model.StudyRundown, error)
-	GetListUstadName() ([]model.User, error)
-	GetListStudies(list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error)
-	DetailStudy(ID uint) (model.StudyRundown, error)
-	DeleteStudy(ID uint) error
-	UpdateStudy(study model.StudyRundown) (model.StudyRundown, error)
+	AddStudy(ctx context.Context, study model.StudyRundown) (model.StudyRundown, error)
+	GetListUstadName(ctx context.Context) ([]model.User, error)
+	GetListStudies(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error)
+	DetailStudy(ctx context.Context, ID uint) (model.StudyRundown, error)
+	DeleteStudy(ctx context.Context, ID uint) error
+	UpdateStudy(ctx context.Context, study model.StudyRundown) (model.StudyRundown, error)
 }
 
 type StudyRepositoryImpl struct {
@@ -22,11 +24,11 @@ func NewRepository(db *gorm.DB) *StudyRepositoryImpl {
 	return &StudyRepositoryImpl{db}
 }
 
-func (s *StudyRepositoryImpl) AddStudy(study model.StudyRundown) (model.StudyRundown, error) {
+func (s *StudyRepositoryImpl) AddStudy(ctx context.Context, study model.StudyRundown) (model.StudyRundown, error) {
 	var user model.User
-	err := s.db.Create(&study).Error
+	err := s.db.WithContext(ctx).Create(&study).Error
 
-	s.db.Where("id = ?", study.UserID).Find(&user)
+	s.db.WithContext(ctx).Where("id = ?", study.UserID).Find(&user)
 	study.User = model.User{Name: user.Name}
 
 	if err != nil {
@@ -35,17 +37,17 @@ func (s *StudyRepositoryImpl) AddStudy(study model.StudyRundown) (model.StudyRun
 	return study, nil
 }
 
-func (s *StudyRepositoryImpl) GetListUstadName() ([]model.User, error) {
+func (s *StudyRepositoryImpl) GetListUstadName(ctx context.Context) ([]model.User, error) {
 	var users []model.User
 	var role model.Role
 	var ustadzName []model.User
 
-	err := s.db.Find(&users).Error
+	err := s.db.WithContext(ctx).Find(&users).Error
 	if err != nil {
 		return users, err
 	}
 	for _, user := range users {
-		s.db.Where("id = ?", user.RoleID).Find(&role)
+		s.db.WithContext(ctx).Where("id = ?", user.RoleID).Find(&role)
 		itemUstadzname := model.User{
 			ID:    user.ID,
 			Name:  user.Name,
@@ -62,15 +64,15 @@ func (s *StudyRepositoryImpl) GetListUstadName() ([]model.User, error) {
 
 }
 
-func (s *StudyRepositoryImpl) GetListStudies(list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error) {
+func (s *StudyRepositoryImpl) GetListStudies(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error) {
 	var rundowns []model.StudyRundown
 	var user model.User
 	var listsStudyRundowns []model.StudyRundown
 
-	err := s.db.Scopes(list).Find(&rundowns).Error
+	err := s.db.WithContext(ctx).Scopes(list).Find(&rundowns).Error
 
 	for _, item := range rundowns {
-		s.db.Where("id = ?", item.UserID).Find(&user)
+		s.db.WithContext(ctx).Where("id = ?", item.UserID).Find(&user)
 		itemRundown := model.StudyRundown{
 			ID:           item.ID,
 			Title:        item.Title,
@@ -91,32 +93,32 @@ func (s *StudyRepositoryImpl) GetListStudies(list func(db *gorm.DB) *gorm.DB) ([
 		return listsStudyRundowns, 0, err
 	}
 	totalCount := int64(0)
-	s.db.Find(&rundowns).Count(&totalCount)
+	s.db.WithContext(ctx).Find(&rundowns).Count(&totalCount)
 	return listsStudyRundowns, int(totalCount), nil
 }
 
-func (s *StudyRepositoryImpl) DeleteStudy(ID uint) error {
-	var studyRundown model.StudyRundown
-
-	s.db.Where("id = ?", ID).Find(&studyRundown)
-	err := s.db.Delete(&model.StudyRundown{}, ID).Error
+func (s *StudyRepositoryImpl) DeleteStudy(ctx context.Context, ID uint) error {
+	err := s.db.WithContext(ctx).Delete(&model.StudyRundown{}, ID).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *StudyRepositoryImpl) DetailStudy(ID uint) (model.StudyRundown, error) {
+func (s *StudyRepositoryImpl) DetailStudy(ctx context.Context, ID uint) (model.StudyRundown, error) {
 	var studyRundown model.StudyRundown
-	err := s.db.Preload("User").Where("id = ?", ID).Find(&studyRundown).Error
+	err := s.db.WithContext(ctx).Preload("User").Where("id = ?", ID).First(&studyRundown).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return studyRundown, errNotFound
+	}
 	if err != nil {
 		return studyRundown, err
 	}
 	return studyRundown, nil
 }
 
-func (s *StudyRepositoryImpl) UpdateStudy(study model.StudyRundown) (model.StudyRundown, error) {
-	err := s.db.Save(&study).Error
+func (s *StudyRepositoryImpl) UpdateStudy(ctx context.Context, study model.StudyRundown) (model.StudyRundown, error) {
+	err := s.db.WithContext(ctx).Save(&study).Error
 	if err != nil {
 		return study, err
 	}
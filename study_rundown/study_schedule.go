@@ -0,0 +1,35 @@
+package study_rundown
+
+import (
+	"nurul-iman-blok-m/model"
+	"strings"
+	"time"
+)
+
+// layouts schedule_date and time are accepted in, both are free text in the admin form
+var (
+	dateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006"}
+	timeLayouts = []string{"15:04", "15.04", "15:04:05"}
+)
+
+// StartTime combines schedule_date and time of a rundown, false when they can not be read
+func StartTime(rundown model.StudyRundown, location *time.Location) (time.Time, bool) {
+	for _, dateLayout := range dateLayouts {
+		for _, timeLayout := range timeLayouts {
+			start, err := time.ParseInLocation(dateLayout+" "+timeLayout, strings.TrimSpace(rundown.ScheduleDate)+" "+strings.TrimSpace(rundown.Time), location)
+			if err == nil {
+				return start, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ScheduleDates is the day written in every accepted layout, to look rundowns up by schedule_date
+func ScheduleDates(day time.Time) []string {
+	var dates []string
+	for _, layout := range dateLayouts {
+		dates = append(dates, day.Format(layout))
+	}
+	return dates
+}
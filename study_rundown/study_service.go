@@ -1,28 +1,34 @@
 package study_rundown
 
 import (
+	"context"
 	"gorm.io/gorm"
+	"nurul-iman-blok-m/apperr"
 	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/webhook"
 )
 
+var errNotFound = apperr.NotFound("rundown_not_found", "study rundown not found")
+
 type StudyService interface {
-	AddStudy(input StudyRundownInput) (model.StudyRundown, error)
-	GetListUstadName() ([]model.User, error)
-	GetListStudy(list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error)
-	DetailStudy(input StudyRundownInputDetail) (model.StudyRundown, error)
-	DeleteStudy(input StudyRundownInputDetail) error
-	UpdateStudy(dataUpdate StudyRundownUpdateInput, input StudyRundownInputDetail) (model.StudyRundown, error)
+	AddStudy(ctx context.Context, input StudyRundownInput) (model.StudyRundown, error)
+	GetListUstadName(ctx context.Context) ([]model.User, error)
+	GetListStudy(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error)
+	DetailStudy(ctx context.Context, input StudyRundownInputDetail) (model.StudyRundown, error)
+	DeleteStudy(ctx context.Context, input StudyRundownInputDetail) error
+	UpdateStudy(ctx context.Context, dataUpdate StudyRundownUpdateInput, input StudyRundownInputDetail) (model.StudyRundown, error)
 }
 
 type StudyServiceImpl struct {
 	repository StudyRepository
+	emitter    webhook.Emitter
 }
 
-func NewService(repository StudyRepository) *StudyServiceImpl {
-	return &StudyServiceImpl{repository}
+func NewService(repository StudyRepository, emitter webhook.Emitter) *StudyServiceImpl {
+	return &StudyServiceImpl{repository, emitter}
 }
 
-func (s *StudyServiceImpl) AddStudy(input StudyRundownInput) (model.StudyRundown, error) {
+func (s *StudyServiceImpl) AddStudy(ctx context.Context, input StudyRundownInput) (model.StudyRundown, error) {
 	study := model.StudyRundown{}
 	study.Title = input.Title
 	study.UserID = input.UserID
@@ -30,15 +36,16 @@ func (s *StudyServiceImpl) AddStudy(input StudyRundownInput) (model.StudyRundown
 	study.ScheduleDate = input.ScheduleDate
 	study.OnScheduled = input.OnScheduled
 
-	addStudy, err := s.repository.AddStudy(study)
+	addStudy, err := s.repository.AddStudy(ctx, study)
 	if err != nil {
 		return model.StudyRundown{}, err
 	}
+	s.emitter.Emit(ctx, webhook.EventRundownCreated, StudyResponseFormat(addStudy))
 	return addStudy, nil
 }
 
-func (s *StudyServiceImpl) GetListUstadName() ([]model.User, error) {
-	ustadName, err := s.repository.GetListUstadName()
+func (s *StudyServiceImpl) GetListUstadName(ctx context.Context) ([]model.User, error) {
+	ustadName, err := s.repository.GetListUstadName(ctx)
 	if err != nil {
 		return ustadName, err
 	}
@@ -46,16 +53,16 @@ func (s *StudyServiceImpl) GetListUstadName() ([]model.User, error) {
 	return ustadName, nil
 }
 
-func (s *StudyServiceImpl) GetListStudy(list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error) {
-	rundowns, count, err := s.repository.GetListStudies(list)
+func (s *StudyServiceImpl) GetListStudy(ctx context.Context, list func(db *gorm.DB) *gorm.DB) ([]model.StudyRundown, int, error) {
+	rundowns, count, err := s.repository.GetListStudies(ctx, list)
 	if err != nil {
 		return rundowns, 0, err
 	}
 	return rundowns, count, err
 }
 
-func (s *StudyServiceImpl) DetailStudy(input StudyRundownInputDetail) (model.StudyRundown, error) {
-	data, err := s.repository.DetailStudy(input.ID)
+func (s *StudyServiceImpl) DetailStudy(ctx context.Context, input StudyRundownInputDetail) (model.StudyRundown, error) {
+	data, err := s.repository.DetailStudy(ctx, input.ID)
 	if err != nil {
 		return data, err
 	}
@@ -63,18 +70,19 @@ func (s *StudyServiceImpl) DetailStudy(input StudyRundownInputDetail) (model.Stu
 	return data, nil
 }
 
-func (s *StudyServiceImpl) DeleteStudy(input StudyRundownInputDetail) error {
-	err := s.repository.DeleteStudy(input.ID)
+func (s *StudyServiceImpl) DeleteStudy(ctx context.Context, input StudyRundownInputDetail) error {
+	err := s.repository.DeleteStudy(ctx, input.ID)
 	if err != nil {
 		return err
 	}
+	s.emitter.Emit(ctx, webhook.EventRundownDeleted, webhook.DeletedData{ID: input.ID})
 	return nil
 }
 
-func (s *StudyServiceImpl) UpdateStudy(dataUpdate StudyRundownUpdateInput, input StudyRundownInputDetail) (model.StudyRundown, error) {
-	data, err := s.repository.DetailStudy(input.ID)
+func (s *StudyServiceImpl) UpdateStudy(ctx context.Context, dataUpdate StudyRundownUpdateInput, input StudyRundownInputDetail) (model.StudyRundown, error) {
+	data, err := s.repository.DetailStudy(ctx, input.ID)
 	if err != nil {
-		return data, nil
+		return data, err
 	}
 
 	if dataUpdate.Title != "" {
@@ -90,10 +98,11 @@ func (s *StudyServiceImpl) UpdateStudy(dataUpdate StudyRundownUpdateInput, input
 
 	data.OnScheduled = dataUpdate.OnScheduled
 
-	update, errUpdate := s.repository.UpdateStudy(data)
+	update, errUpdate := s.repository.UpdateStudy(ctx, data)
 	if errUpdate != nil {
 		return update, errUpdate
 	}
+	s.emitter.Emit(ctx, webhook.EventRundownUpdated, StudyResponseFormat(update))
 
 	return update, nil
 }
@@ -0,0 +1,5 @@
+package trash
+
+type TrashRestoreInput struct {
+	ID uint `uri:"id" binding:"required"`
+}
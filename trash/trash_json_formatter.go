@@ -0,0 +1,31 @@
+package trash
+
+import "time"
+
+type TrashFormatter struct {
+	Resource  string    `json:"resource"`
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+func TrashJsonFormatter(item TrashItem, retention time.Duration) TrashFormatter {
+	return TrashFormatter{
+		Resource:  item.Resource,
+		ID:        item.ID,
+		Title:     item.Title,
+		DeletedAt: item.DeletedAt,
+		PurgeAt:   item.DeletedAt.Add(retention),
+	}
+}
+
+func TrashListJsonFormatter(items []TrashItem, retention time.Duration) []TrashFormatter {
+	formatter := []TrashFormatter{}
+
+	for _, item := range items {
+		formatter = append(formatter, TrashJsonFormatter(item, retention))
+	}
+
+	return formatter
+}
@@ -0,0 +1,184 @@
+package trash
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/media"
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+const (
+	ResourceAnnouncement = "announcements"
+	ResourceRundown      = "rundown"
+	ResourceArticle      = "articles"
+)
+
+type TrashItem struct {
+	Resource  string
+	ID        uint
+	Title     string
+	DeletedAt time.Time
+}
+
+type TrashRepository interface {
+	GetTrash(ctx context.Context, resource string) ([]TrashItem, error)
+	Restore(ctx context.Context, resource string, ID uint) error
+	PurgeAnnouncements(ctx context.Context, before time.Time) (int, error)
+	Purge(ctx context.Context, resource string, before time.Time) (int, error)
+}
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *trashRepository {
+	return &trashRepository{db}
+}
+
+func resourceModel(resource string) (interface{}, error) {
+	switch resource {
+	case ResourceAnnouncement:
+		return &model.Announcement{}, nil
+	case ResourceRundown:
+		return &model.StudyRundown{}, nil
+	case ResourceArticle:
+		return &model.Article{}, nil
+	}
+	return nil, apperr.Validation("unknown_trash_resource", "unknown trash resource")
+}
+
+func (r *trashRepository) GetTrash(ctx context.Context, resource string) ([]TrashItem, error) {
+	resources := []string{ResourceAnnouncement, ResourceRundown, ResourceArticle}
+	if resource != "" {
+		resources = []string{resource}
+	}
+
+	var items []TrashItem
+	for _, itemResource := range resources {
+		trashModel, err := resourceModel(itemResource)
+		if err != nil {
+			return items, err
+		}
+
+		var deleted []TrashItem
+		err = r.db.WithContext(ctx).Unscoped().Model(trashModel).
+			Select("id, title, deleted_at").
+			Where("deleted_at IS NOT NULL").
+			Order("deleted_at desc").
+			Scan(&deleted).Error
+		if err != nil {
+			return items, err
+		}
+
+		for _, item := range deleted {
+			item.Resource = itemResource
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+func (r *trashRepository) Restore(ctx context.Context, resource string, ID uint) error {
+	trashModel, err := resourceModel(resource)
+	if err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).Unscoped().Model(trashModel).
+		Where("id = ? AND deleted_at IS NOT NULL", ID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("trash_item_not_found", "item not found in trash")
+	}
+
+	return nil
+}
+
+// PurgeAnnouncements removes trashed announcements with their revisions, each in its own transaction.
+// Banners are left to the media garbage collector once no other entity refers to them
+func (r *trashRepository) PurgeAnnouncements(ctx context.Context, before time.Time) (int, error) {
+	var announcementIDs []uint
+	err := r.db.WithContext(ctx).Unscoped().Model(&model.Announcement{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Pluck("id", &announcementIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, ID := range announcementIDs {
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var revisionIDs []uint
+			errRevisionIDs := tx.Model(&model.AnnouncementRevision{}).Where("announcement_id = ?", ID).Pluck("id", &revisionIDs).Error
+			if errRevisionIDs != nil {
+				return errRevisionIDs
+			}
+
+			errReferences := tx.Where("entity_type = ? AND entity_id = ?", media.EntityAnnouncement, ID).Delete(&model.MediaReference{}).Error
+			if errReferences != nil {
+				return errReferences
+			}
+			if len(revisionIDs) > 0 {
+				errReferences = tx.Where("entity_type = ? AND entity_id IN ?", media.EntityAnnouncementRevision, revisionIDs).Delete(&model.MediaReference{}).Error
+				if errReferences != nil {
+					return errReferences
+				}
+			}
+
+			errRevisions := tx.Where("announcement_id = ?", ID).Delete(&model.AnnouncementRevision{}).Error
+			if errRevisions != nil {
+				return errRevisions
+			}
+			return tx.Unscoped().Delete(&model.Announcement{}, ID).Error
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+func (r *trashRepository) Purge(ctx context.Context, resource string, before time.Time) (int, error) {
+	trashModel, err := resourceModel(resource)
+	if err != nil {
+		return 0, err
+	}
+
+	if resource == ResourceRundown {
+		errRecordings := r.purgeRecordings(ctx, before)
+		if errRecordings != nil {
+			return 0, errRecordings
+		}
+	}
+
+	result := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(trashModel)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// purgeRecordings removes the recordings of rundowns about to be purged,
+// their audio is left to the media garbage collector once the references are gone
+func (r *trashRepository) purgeRecordings(ctx context.Context, before time.Time) error {
+	var recordingIDs []uint
+	err := r.db.WithContext(ctx).Model(&model.AudioRecording{}).
+		Where("study_rundown_id IN (?)", r.db.WithContext(ctx).Unscoped().Model(&model.StudyRundown{}).Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", before)).
+		Pluck("id", &recordingIDs).Error
+	if err != nil || len(recordingIDs) == 0 {
+		return err
+	}
+
+	err = r.db.WithContext(ctx).Where("entity_type = ? AND entity_id IN ?", media.EntityRecording, recordingIDs).Delete(&model.MediaReference{}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Delete(&model.AudioRecording{}, recordingIDs).Error
+}
@@ -0,0 +1,20 @@
+package trash
+
+import (
+	"context"
+	"log"
+	"nurul-iman-blok-m/worker"
+	"time"
+)
+
+// StartPurgeScheduler runs PurgeExpired in the background every interval
+func StartPurgeScheduler(jobs *worker.Group, service TrashService, interval time.Duration) {
+	jobs.Every(interval, true, func(ctx context.Context) {
+		purged, err := service.PurgeExpired(ctx)
+		if err != nil {
+			log.Printf("trash purge error: %v", err)
+		} else if purged > 0 {
+			log.Printf("trash purge: %d items deleted permanently", purged)
+		}
+	})
+}
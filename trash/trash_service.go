@@ -0,0 +1,62 @@
+package trash
+
+import (
+	"context"
+	"time"
+)
+
+type TrashService interface {
+	GetTrash(ctx context.Context, resource string) ([]TrashItem, error)
+	Restore(ctx context.Context, resource string, input TrashRestoreInput) error
+	PurgeExpired(ctx context.Context) (int, error)
+	RetentionPeriod() time.Duration
+}
+
+type trashService struct {
+	repository TrashRepository
+	retention  time.Duration
+}
+
+func NewService(repository TrashRepository, retention time.Duration) *trashService {
+	return &trashService{repository, retention}
+}
+
+func (s *trashService) GetTrash(ctx context.Context, resource string) ([]TrashItem, error) {
+	items, err := s.repository.GetTrash(ctx, resource)
+	if err != nil {
+		return items, err
+	}
+	return items, nil
+}
+
+func (s *trashService) Restore(ctx context.Context, resource string, input TrashRestoreInput) error {
+	err := s.repository.Restore(ctx, resource, input.ID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// PurgeExpired permanently deletes every trashed row older than the retention period
+func (s *trashService) PurgeExpired(ctx context.Context) (int, error) {
+	before := time.Now().Add(-s.retention)
+
+	total, err := s.repository.PurgeAnnouncements(ctx, before)
+	if err != nil {
+		return total, err
+	}
+
+	for _, resource := range []string{ResourceRundown, ResourceArticle} {
+		purged, errPurge := s.repository.Purge(ctx, resource, before)
+		total += purged
+		if errPurge != nil {
+			return total, errPurge
+		}
+	}
+
+	return total, nil
+}
+
+func (s *trashService) RetentionPeriod() time.Duration {
+	return s.retention
+}
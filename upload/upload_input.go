@@ -0,0 +1,20 @@
+package upload
+
+type PresignInput struct {
+	FileName    string `json:"file_name" binding:"required"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+	EntityType  string `json:"entity_type" binding:"required,oneof=announcement rundown article video recording"`
+}
+
+type CompleteInput struct {
+	Key        string `json:"key" binding:"required"`
+	EntityType string `json:"entity_type" binding:"required,oneof=announcement rundown article video"`
+	EntityID   uint   `json:"entity_id" binding:"required"`
+}
+
+type LocalUploadInput struct {
+	Key       string `form:"key" binding:"required"`
+	Expires   string `form:"expires" binding:"required"`
+	Signature string `form:"signature" binding:"required"`
+}
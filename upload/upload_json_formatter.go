@@ -0,0 +1,66 @@
+package upload
+
+import (
+	"net/http"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/storage"
+	"time"
+)
+
+type PresignFormatter struct {
+	Key       string            `json:"key"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type UploadFormatter struct {
+	ID          uint      `json:"id"`
+	Key         string    `json:"key"`
+	URL         string    `json:"url"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	EntityType  string    `json:"entity_type"`
+	EntityID    uint      `json:"entity_id"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func PresignJsonFormatter(upload model.Upload, request storage.PresignedRequest) PresignFormatter {
+	return PresignFormatter{
+		Key:       upload.Key,
+		UploadURL: request.URL,
+		Method:    request.Method,
+		Headers:   flattenHeaders(request.Headers),
+		ExpiresAt: request.Expires,
+	}
+}
+
+func UploadJsonFormatter(upload model.Upload) UploadFormatter {
+	return UploadFormatter{
+		ID:          upload.ID,
+		Key:         upload.Key,
+		URL:         upload.URL,
+		FileName:    upload.FileName,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		EntityType:  upload.EntityType,
+		EntityID:    upload.EntityID,
+		UploadedBy:  upload.User.Name,
+		CreatedAt:   upload.CreatedAt,
+	}
+}
+
+// flattenHeaders keeps the signed headers the client has to repeat, host is set by the http client itself
+func flattenHeaders(headers http.Header) map[string]string {
+	flat := map[string]string{}
+	for name, values := range headers {
+		if name == "Host" || len(values) == 0 {
+			continue
+		}
+		flat[name] = values[0]
+	}
+	return flat
+}
@@ -0,0 +1,50 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+)
+
+type UploadRepository interface {
+	SaveUpload(ctx context.Context, upload model.Upload) (model.Upload, error)
+	FindPending(ctx context.Context, key string, userID uint) (model.Upload, error)
+	DeleteUpload(ctx context.Context, ID uint) error
+}
+
+type uploadRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *uploadRepository {
+	return &uploadRepository{db}
+}
+
+func (r *uploadRepository) SaveUpload(ctx context.Context, upload model.Upload) (model.Upload, error) {
+	err := r.db.WithContext(ctx).Omit("User").Save(&upload).Error
+	if err != nil {
+		return upload, err
+	}
+	return upload, nil
+}
+
+func (r *uploadRepository) FindPending(ctx context.Context, key string, userID uint) (model.Upload, error) {
+	var upload model.Upload
+	err := r.db.WithContext(ctx).Where("object_key = ? AND user_id = ? AND status = ?", key, userID, StatusPending).First(&upload).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return upload, errNotFound
+	}
+	if err != nil {
+		return upload, err
+	}
+	return upload, nil
+}
+
+func (r *uploadRepository) DeleteUpload(ctx context.Context, ID uint) error {
+	err := r.db.WithContext(ctx).Delete(&model.Upload{}, ID).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
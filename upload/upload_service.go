@@ -0,0 +1,248 @@
+package upload
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/media"
+	"nurul-iman-blok-m/metrics"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/storage"
+	"strings"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+
+	EntityAnnouncement = "announcement"
+	EntityRundown      = "rundown"
+	EntityArticle      = "article"
+	EntityVideo        = "video"
+	EntityRecording    = "recording"
+
+	// MaxSize is the largest file accepted through a presigned upload, long kajian audio fits
+	MaxSize = 200 << 20
+
+	presignExpiry = 15 * time.Minute
+)
+
+// allowed content types and the extension used in the key
+var errNotFound = apperr.NotFound("upload_not_found", "upload not found")
+
+var allowedTypes = map[string]string{
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/webp":      "webp",
+	"application/pdf": "pdf",
+	"audio/mpeg":      "mp3",
+	"audio/mp4":       "m4a",
+	"audio/aac":       "aac",
+	"audio/ogg":       "ogg",
+	"audio/wav":       "wav",
+}
+
+// sniffSize is how much http.DetectContentType looks at
+const sniffSize = 512
+
+// what http.DetectContentType reports for the allowed types it knows, aac has no signature it recognises
+var sniffedTypes = map[string]string{
+	"image/jpeg":      "image/jpeg",
+	"image/png":       "image/png",
+	"image/webp":      "image/webp",
+	"application/pdf": "application/pdf",
+	"audio/mpeg":      "audio/mpeg",
+	"audio/mp4":       "video/mp4",
+	"audio/ogg":       "application/ogg",
+	"audio/wav":       "audio/wave",
+}
+
+var entityPrefixes = map[string]string{
+	EntityAnnouncement: storage.PrefixAnnouncements,
+	EntityRundown:      storage.PrefixRundowns,
+	EntityArticle:      storage.PrefixArticles,
+	EntityVideo:        storage.PrefixVideos,
+	EntityRecording:    storage.PrefixRecordings,
+}
+
+type UploadService interface {
+	Presign(ctx context.Context, input PresignInput, userID uint) (model.Upload, storage.PresignedRequest, error)
+	Complete(ctx context.Context, input CompleteInput, userID uint) (model.Upload, error)
+	Claim(ctx context.Context, key string, userID uint) (model.Upload, error)
+}
+
+type uploadService struct {
+	repository   UploadRepository
+	storage      storage.Storage
+	mediaService media.MediaService
+}
+
+func NewService(repository UploadRepository, storage storage.Storage, mediaService media.MediaService) *uploadService {
+	return &uploadService{repository, storage, mediaService}
+}
+
+func (s *uploadService) Presign(ctx context.Context, input PresignInput, userID uint) (model.Upload, storage.PresignedRequest, error) {
+	contentType, extension, err := normalizeContentType(input.ContentType)
+	if err != nil {
+		return model.Upload{}, storage.PresignedRequest{}, err
+	}
+	if input.Size > MaxSize {
+		return model.Upload{}, storage.PresignedRequest{}, apperr.Validation("file_too_large", "file too large, max 200MB")
+	}
+	if input.EntityType == EntityRecording && !strings.HasPrefix(contentType, "audio/") {
+		return model.Upload{}, storage.PresignedRequest{}, apperr.Validation("not_audio", "recordings must be audio files")
+	}
+
+	key := storage.RandomKey(entityPrefixes[input.EntityType]+"/"+storage.PrefixUploads, extension)
+	request, err := s.storage.PresignPut(ctx, key, contentType, presignExpiry)
+	if err != nil {
+		return model.Upload{}, request, err
+	}
+
+	upload := model.Upload{}
+	upload.Key = key
+	upload.FileName = input.FileName
+	upload.ContentType = contentType
+	upload.Size = input.Size
+	upload.Status = StatusPending
+	upload.EntityType = input.EntityType
+	upload.UserID = userID
+	upload.ExpiresAt = request.Expires
+
+	saved, err := s.repository.SaveUpload(ctx, upload)
+	if err != nil {
+		return saved, request, err
+	}
+	return saved, request, nil
+}
+
+// Complete checks the object really landed in storage as announced before attaching it
+func (s *uploadService) Complete(ctx context.Context, input CompleteInput, userID uint) (model.Upload, error) {
+	upload, asset, err := s.verify(ctx, input.Key, userID)
+	if err != nil {
+		return upload, err
+	}
+
+	err = s.mediaService.Attach(ctx, media.MediaDetailInput{ID: asset.ID}, media.AttachInput{EntityType: input.EntityType, EntityID: input.EntityID})
+	if err != nil {
+		return upload, err
+	}
+
+	upload.Status = StatusCompleted
+	upload.EntityType = input.EntityType
+	upload.EntityID = input.EntityID
+
+	saved, err := s.repository.SaveUpload(ctx, upload)
+	if err != nil {
+		return saved, err
+	}
+	return saved, nil
+}
+
+// Claim completes an upload for an entity that keeps the file url itself instead of listing it as attachment,
+// the caller is responsible for referencing the media asset
+func (s *uploadService) Claim(ctx context.Context, key string, userID uint) (model.Upload, error) {
+	upload, _, err := s.verify(ctx, key, userID)
+	if err != nil {
+		return upload, err
+	}
+
+	upload.Status = StatusCompleted
+
+	saved, err := s.repository.SaveUpload(ctx, upload)
+	if err != nil {
+		return saved, err
+	}
+	return saved, nil
+}
+
+// verify makes sure the pending upload exists in storage with the announced type and size,
+// completed uploads land in the media library so they can be reused on other entities
+func (s *uploadService) verify(ctx context.Context, key string, userID uint) (model.Upload, model.MediaAsset, error) {
+	upload, err := s.repository.FindPending(ctx, key, userID)
+	if err != nil {
+		return upload, model.MediaAsset{}, err
+	}
+
+	info, err := s.storage.Stat(ctx, upload.Key)
+	if err == storage.ErrNotFound {
+		metrics.UploadFailed(metrics.UploadPresigned, "missing")
+		return upload, model.MediaAsset{}, apperr.Conflict("upload_incomplete", "file has not been uploaded yet")
+	}
+	if err != nil {
+		metrics.UploadFailed(metrics.UploadPresigned, "error")
+		return upload, model.MediaAsset{}, err
+	}
+
+	matches := info.Size > 0 && info.Size <= MaxSize && strings.HasPrefix(info.ContentType, upload.ContentType)
+	if matches {
+		// the content type header is whatever the client sent, the first bytes tell what the file really is
+		head, errRead := s.storage.ReadStart(ctx, upload.Key, sniffSize)
+		if errRead != nil {
+			metrics.UploadFailed(metrics.UploadPresigned, "error")
+			return upload, model.MediaAsset{}, errRead
+		}
+		matches = sniffMatches(upload.ContentType, head)
+	}
+	if !matches {
+		// whatever was uploaded does not match the presign request, drop it
+		metrics.UploadFailed(metrics.UploadPresigned, "mismatch")
+		_ = s.storage.Delete(ctx, upload.Key)
+		_ = s.repository.DeleteUpload(ctx, upload.ID)
+		return upload, model.MediaAsset{}, apperr.Validation("upload_mismatch", "uploaded file does not match the requested type or size")
+	}
+
+	upload.URL = s.storage.URL(upload.Key)
+	upload.Size = info.Size
+	metrics.ObserveUpload(metrics.UploadPresigned, info.Size)
+
+	asset, err := s.mediaService.RegisterAsset(ctx, media.AssetInput{
+		Key:         upload.Key,
+		URL:         upload.URL,
+		FileName:    upload.FileName,
+		ContentType: upload.ContentType,
+		Size:        info.Size,
+		UserID:      userID,
+	})
+	if err != nil {
+		return upload, asset, err
+	}
+	return upload, asset, nil
+}
+
+// sniffMatches compares the start of the file with the type the client announced, so a presigned
+// PUT labelled image/png can not leave a script or an archive in the media library
+func sniffMatches(contentType string, head []byte) bool {
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if detected == sniffedTypes[contentType] {
+		return true
+	}
+
+	switch contentType {
+	case "audio/mpeg":
+		// mp3 without an ID3 tag starts right away with an MPEG audio frame
+		return len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0
+	case "audio/mp4":
+		// m4a files often list only M4A brands, which the mp4 sniffer does not know
+		return len(head) >= 8 && string(head[4:8]) == "ftyp"
+	case "audio/aac":
+		// raw aac starts with an ADTS frame header
+		return len(head) >= 2 && head[0] == 0xFF && head[1]&0xF6 == 0xF0
+	}
+	return false
+}
+
+func normalizeContentType(contentType string) (string, string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", "", apperr.Validation("invalid_content_type", "invalid content type")
+	}
+
+	extension, ok := allowedTypes[mediaType]
+	if !ok {
+		return "", "", apperr.Validation("file_type_not_allowed", "file type is not allowed")
+	}
+	return mediaType, extension, nil
+}
@@ -0,0 +1,37 @@
+package upload
+
+import "testing"
+
+func TestSniffMatches(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	m4a := []byte("\x00\x00\x00\x1cftypM4A \x00\x00\x00\x00M4A isomiso2")
+
+	tests := []struct {
+		name        string
+		contentType string
+		head        []byte
+		want        bool
+	}{
+		{"png", "image/png", png, true},
+		{"jpeg", "image/jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), true},
+		{"webp", "image/webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), true},
+		{"pdf", "application/pdf", []byte("%PDF-1.7\n"), true},
+		{"mp3 with id3 tag", "audio/mpeg", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), true},
+		{"mp3 frame", "audio/mpeg", []byte("\xff\xfb\x90\x64"), true},
+		{"aac frame", "audio/aac", []byte("\xff\xf1\x50\x80"), true},
+		{"m4a", "audio/mp4", m4a, true},
+		{"ogg", "audio/ogg", []byte("OggS\x00\x02\x00\x00"), true},
+		{"wav", "audio/wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), true},
+		{"png labelled jpeg", "image/jpeg", png, false},
+		{"html labelled png", "image/png", []byte("<html><script>alert(1)</script>"), false},
+		{"zip labelled pdf", "application/pdf", []byte("PK\x03\x04\x14\x00"), false},
+		{"text labelled mp3", "audio/mpeg", []byte("just some text"), false},
+		{"empty", "image/png", nil, false},
+	}
+
+	for _, test := range tests {
+		if got := sniffMatches(test.contentType, test.head); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
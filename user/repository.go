@@ -1,15 +1,16 @@
 package user
 
 import (
+	"context"
 	"gorm.io/gorm"
 	"nurul-iman-blok-m/model"
 )
 
 type UserRepository interface {
-	SaveUser(user model.User) (model.User, error)
-	FindByID(ID uint) (model.User, error)
-	FindByEmail(email string) (model.User, error)
-	GetRoleForResponse(user model.User) (model.User, error)
+	SaveUser(ctx context.Context, user model.User) (model.User, error)
+	FindByID(ctx context.Context, ID uint) (model.User, error)
+	FindByEmail(ctx context.Context, email string) (model.User, error)
+	GetRoleForResponse(ctx context.Context, user model.User) (model.User, error)
 }
 
 type userRepository struct {
@@ -20,10 +21,10 @@ func NewRepository(db *gorm.DB) *userRepository {
 	return &userRepository{db}
 }
 
-func (r *userRepository) SaveUser(user model.User) (model.User, error) {
+func (r *userRepository) SaveUser(ctx context.Context, user model.User) (model.User, error) {
 	var role model.Role
-	err := r.db.Create(&user).Error
-	r.db.Where("id = ?", user.RoleID).Find(&role)
+	err := r.db.WithContext(ctx).Create(&user).Error
+	r.db.WithContext(ctx).Where("id = ?", user.RoleID).Find(&role)
 
 	user.Role = model.Role{RoleName: role.RoleName}
 	if err != nil {
@@ -33,22 +34,22 @@ func (r *userRepository) SaveUser(user model.User) (model.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) FindByID(ID uint) (model.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, ID uint) (model.User, error) {
 	var user model.User
 	var role model.Role
-	err := r.db.Where("id = ?", ID).Find(&user).Error
+	err := r.db.WithContext(ctx).Where("id = ?", ID).Find(&user).Error
 	if err != nil {
 		return user, err
 	}
-	r.db.Where("id = ?", user.RoleID).Find(&role)
+	r.db.WithContext(ctx).Where("id = ?", user.RoleID).Find(&role)
 	user.Role = model.Role{RoleName: role.RoleName}
 
 	return user, nil
 }
 
-func (r *userRepository) FindByEmail(email string) (model.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).Find(&user).Error
+	err := r.db.WithContext(ctx).Where("email = ?", email).Find(&user).Error
 	if err != nil {
 		return user, err
 	}
@@ -56,9 +57,9 @@ func (r *userRepository) FindByEmail(email string) (model.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) GetRoleForResponse(user model.User) (model.User, error) {
+func (r *userRepository) GetRoleForResponse(ctx context.Context, user model.User) (model.User, error) {
 	userRole := user
-	err := r.db.Preload("Role").Find(&userRole).Error
+	err := r.db.WithContext(ctx).Preload("Role").Find(&userRole).Error
 	if err != nil {
 		return userRole, err
 	}
@@ -1,15 +1,19 @@
 package user
 
 import (
-	"errors"
+	"context"
 	"golang.org/x/crypto/bcrypt"
+	"nurul-iman-blok-m/apperr"
 	"nurul-iman-blok-m/model"
 )
 
+// errInvalidCredentials does not tell whether the email or the password was wrong
+var errInvalidCredentials = apperr.Unauthorized("invalid_credentials", "email or password is incorrect")
+
 type UserService interface {
-	RegisterUser(input RegisterUserInput) (model.User, string, error)
-	GetUserByID(ID uint) (model.User, error)
-	LoginUser(input LoginUserInput) (model.User, string, error)
+	RegisterUser(ctx context.Context, input RegisterUserInput) (model.User, string, error)
+	GetUserByID(ctx context.Context, ID uint) (model.User, error)
+	LoginUser(ctx context.Context, input LoginUserInput) (model.User, string, error)
 }
 
 type userService struct {
@@ -20,7 +24,7 @@ func NewService(repository UserRepository) *userService {
 	return &userService{repository}
 }
 
-func (u *userService) RegisterUser(input RegisterUserInput) (model.User, string, error) {
+func (u *userService) RegisterUser(ctx context.Context, input RegisterUserInput) (model.User, string, error) {
 	user := model.User{}
 	user.Name = input.Name
 	user.Email = input.Email
@@ -31,7 +35,7 @@ func (u *userService) RegisterUser(input RegisterUserInput) (model.User, string,
 	user.Password = string(passwordHash)
 	user.RoleID = input.RoleID
 
-	newUser, errUser := u.repository.SaveUser(user)
+	newUser, errUser := u.repository.SaveUser(ctx, user)
 	if errUser != nil {
 		return newUser, "", errUser
 	}
@@ -39,37 +43,37 @@ func (u *userService) RegisterUser(input RegisterUserInput) (model.User, string,
 	return newUser, newUser.Role.RoleName, nil
 }
 
-func (u *userService) GetUserByID(ID uint) (model.User, error) {
-	user, err := u.repository.FindByID(ID)
+func (u *userService) GetUserByID(ctx context.Context, ID uint) (model.User, error) {
+	user, err := u.repository.FindByID(ctx, ID)
 	if err != nil {
 		return user, err
 	}
 	if user.ID == 0 {
-		return user, errors.New("no user found on with that id")
+		return user, apperr.NotFound("user_not_found", "no user found on with that id")
 	}
 	return user, nil
 }
 
-func (u *userService) LoginUser(input LoginUserInput) (model.User, string, error) {
+func (u *userService) LoginUser(ctx context.Context, input LoginUserInput) (model.User, string, error) {
 	email := input.Email
 	passwordInput := input.Password
 
-	user, err := u.repository.FindByEmail(email)
+	user, err := u.repository.FindByEmail(ctx, email)
 	if err != nil {
 		return user, "", err
 	}
 
 	if user.ID == 0 {
-		return user, "", errors.New("no User Found on that email")
+		return user, "", errInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(passwordInput))
 
 	if err != nil {
-		return user, "", err
+		return user, "", errInvalidCredentials
 	}
 
-	roleName, _ := u.repository.GetRoleForResponse(user)
+	roleName, _ := u.repository.GetRoleForResponse(ctx, user)
 
 	return user, roleName.Role.RoleName, nil
 }
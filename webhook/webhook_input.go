@@ -0,0 +1,14 @@
+package webhook
+
+type EndpointInput struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,required"`
+}
+
+type EndpointDetailInput struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type DeliveryListInput struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+}
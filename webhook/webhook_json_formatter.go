@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"nurul-iman-blok-m/model"
+	"strings"
+	"time"
+)
+
+type EndpointFormatter struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DeliveryFormatter struct {
+	ID             uint       `json:"id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status"`
+	Error          string     `json:"error"`
+	Payload        string     `json:"payload"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func EndpointJsonFormatter(endpoint model.WebhookEndpoint) EndpointFormatter {
+	return EndpointFormatter{
+		ID:        endpoint.ID,
+		URL:       endpoint.URL,
+		Events:    strings.Split(endpoint.Events, ","),
+		Active:    endpoint.Active,
+		CreatedBy: endpoint.User.Name,
+		CreatedAt: endpoint.CreatedAt,
+	}
+}
+
+// CreatedEndpointJsonFormatter includes the secret, the only time it is handed out
+func CreatedEndpointJsonFormatter(endpoint model.WebhookEndpoint) EndpointFormatter {
+	formatter := EndpointJsonFormatter(endpoint)
+	formatter.Secret = endpoint.Secret
+	return formatter
+}
+
+func ListEndpointJsonFormatter(endpoints []model.WebhookEndpoint) []EndpointFormatter {
+	formatter := []EndpointFormatter{}
+
+	for _, endpoint := range endpoints {
+		formatter = append(formatter, EndpointJsonFormatter(endpoint))
+	}
+
+	return formatter
+}
+
+func DeliveryJsonFormatter(delivery model.WebhookDelivery) DeliveryFormatter {
+	return DeliveryFormatter{
+		ID:             delivery.ID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		Payload:        delivery.Payload,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+func ListDeliveryJsonFormatter(deliveries []model.WebhookDelivery) []DeliveryFormatter {
+	formatter := []DeliveryFormatter{}
+
+	for _, delivery := range deliveries {
+		formatter = append(formatter, DeliveryJsonFormatter(delivery))
+	}
+
+	return formatter
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"nurul-iman-blok-m/model"
+	"time"
+)
+
+type WebhookRepository interface {
+	SaveEndpoint(ctx context.Context, endpoint model.WebhookEndpoint) (model.WebhookEndpoint, error)
+	GetEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error)
+	FindEndpoint(ctx context.Context, ID uint) (model.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, ID uint) error
+	GetActiveEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error)
+	SaveDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error)
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	CountPendingDeliveries(ctx context.Context) (int64, error)
+	GetDeliveries(ctx context.Context, endpointID uint, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.WebhookDelivery, int, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *webhookRepository {
+	return &webhookRepository{db}
+}
+
+func (r *webhookRepository) SaveEndpoint(ctx context.Context, endpoint model.WebhookEndpoint) (model.WebhookEndpoint, error) {
+	err := r.db.WithContext(ctx).Omit("User").Save(&endpoint).Error
+	if err != nil {
+		return endpoint, err
+	}
+	return endpoint, nil
+}
+
+func (r *webhookRepository) GetEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	err := r.db.WithContext(ctx).Preload("User").Order("created_at desc").Find(&endpoints).Error
+	if err != nil {
+		return endpoints, err
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepository) FindEndpoint(ctx context.Context, ID uint) (model.WebhookEndpoint, error) {
+	var endpoint model.WebhookEndpoint
+	err := r.db.WithContext(ctx).Preload("User").Where("id = ?", ID).First(&endpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return endpoint, errNotFound
+	}
+	if err != nil {
+		return endpoint, err
+	}
+	return endpoint, nil
+}
+
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, ID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("webhook_endpoint_id = ?", ID).Delete(&model.WebhookDelivery{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.WebhookEndpoint{}, ID).Error
+	})
+}
+
+func (r *webhookRepository) GetActiveEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	err := r.db.WithContext(ctx).Where("active = ?", true).Find(&endpoints).Error
+	if err != nil {
+		return endpoints, err
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepository) SaveDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	err := r.db.WithContext(ctx).Omit("WebhookEndpoint").Save(&delivery).Error
+	if err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (r *webhookRepository) CountPendingDeliveries(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("status = ?", StatusPending).Count(&count).Error
+	return count, err
+}
+
+func (r *webhookRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).Preload("WebhookEndpoint").
+		Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+		Order("next_attempt_at asc").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return deliveries, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) GetDeliveries(ctx context.Context, endpointID uint, filter func(db *gorm.DB) *gorm.DB, list func(db *gorm.DB) *gorm.DB) ([]model.WebhookDelivery, int, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("webhook_endpoint_id = ?", endpointID).
+		Scopes(filter, list).
+		Order("created_at desc").
+		Find(&deliveries).Error
+	if err != nil {
+		return deliveries, 0, err
+	}
+
+	totalCount := int64(0)
+	r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("webhook_endpoint_id = ?", endpointID).Scopes(filter).Count(&totalCount)
+	return deliveries, int(totalCount), nil
+}
@@ -0,0 +1,333 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"nurul-iman-blok-m/apperr"
+	"nurul-iman-blok-m/model"
+	"nurul-iman-blok-m/worker"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EventAnnouncementCreated = "announcement.created"
+	EventAnnouncementUpdated = "announcement.updated"
+	EventAnnouncementDeleted = "announcement.deleted"
+	EventRundownCreated      = "rundown.created"
+	EventRundownUpdated      = "rundown.updated"
+	EventRundownDeleted      = "rundown.deleted"
+	// EventDonationReceived can already be subscribed to, it is emitted once donations are recorded in the api
+	EventDonationReceived = "donation.received"
+
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+
+	// MaxAttempts gives up after about four hours of retries with the backoff below
+	MaxAttempts = 10
+
+	baseBackoff  = 30 * time.Second
+	maxBackoff   = 6 * time.Hour
+	batchSize    = 50
+	maxLogLength = 2000
+)
+
+var Events = []string{
+	EventAnnouncementCreated,
+	EventAnnouncementUpdated,
+	EventAnnouncementDeleted,
+	EventRundownCreated,
+	EventRundownUpdated,
+	EventRundownDeleted,
+	EventDonationReceived,
+}
+
+var errNotFound = apperr.NotFound("webhook_not_found", "webhook not found")
+
+// Emitter is what content services call when something happened, delivery happens in the background
+type Emitter interface {
+	Emit(ctx context.Context, event string, data interface{})
+}
+
+type WebhookService interface {
+	Emitter
+	CreateEndpoint(ctx context.Context, input EndpointInput, userID uint) (model.WebhookEndpoint, error)
+	GetEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, input EndpointDetailInput) error
+	GetDeliveries(ctx context.Context, input EndpointDetailInput, filter DeliveryListInput, list func(db *gorm.DB) *gorm.DB) ([]model.WebhookDelivery, int, error)
+	ProcessDue(ctx context.Context) (int, error)
+	CountPending(ctx context.Context) (int64, error)
+}
+
+type webhookService struct {
+	repository WebhookRepository
+	client     *http.Client
+}
+
+func NewService(repository WebhookRepository) *webhookService {
+	return &webhookService{repository, newClient()}
+}
+
+// Envelope is the json body posted to every endpoint
+type Envelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// DeletedData is the payload of *.deleted events, the record itself is gone
+type DeletedData struct {
+	ID uint `json:"id"`
+}
+
+// CreateEndpoint generates the signing secret, it is only shown in the create response
+func (s *webhookService) CreateEndpoint(ctx context.Context, input EndpointInput, userID uint) (model.WebhookEndpoint, error) {
+	errURL := validateURL(input.URL)
+	if errURL != nil {
+		return model.WebhookEndpoint{}, apperr.Validation("invalid_webhook_url", errURL.Error())
+	}
+	for _, event := range input.Events {
+		if !knownEvent(event) {
+			return model.WebhookEndpoint{}, apperr.Validation("unknown_event", "unknown webhook event: "+event)
+		}
+	}
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return model.WebhookEndpoint{}, err
+	}
+
+	endpoint := model.WebhookEndpoint{
+		URL:    input.URL,
+		Secret: hex.EncodeToString(secret),
+		Events: strings.Join(input.Events, ","),
+		Active: true,
+		UserID: userID,
+	}
+
+	saved, err := s.repository.SaveEndpoint(ctx, endpoint)
+	if err != nil {
+		return saved, err
+	}
+	return saved, nil
+}
+
+func (s *webhookService) GetEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	endpoints, err := s.repository.GetEndpoints(ctx)
+	if err != nil {
+		return endpoints, err
+	}
+	return endpoints, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, input EndpointDetailInput) error {
+	_, err := s.repository.FindEndpoint(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+	return s.repository.DeleteEndpoint(ctx, input.ID)
+}
+
+func (s *webhookService) GetDeliveries(ctx context.Context, input EndpointDetailInput, filter DeliveryListInput, list func(db *gorm.DB) *gorm.DB) ([]model.WebhookDelivery, int, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		if filter.Status != "" {
+			db = db.Where("status = ?", filter.Status)
+		}
+		return db
+	}
+
+	deliveries, total, err := s.repository.GetDeliveries(ctx, input.ID, scope, list)
+	if err != nil {
+		return deliveries, total, err
+	}
+	return deliveries, total, nil
+}
+
+// Emit queues one delivery per subscribed endpoint, a failure is logged and never fails the caller
+func (s *webhookService) Emit(ctx context.Context, event string, data interface{}) {
+	err := s.enqueue(ctx, event, data)
+	if err != nil {
+		log.Printf("webhook %s not queued: %v", event, err)
+	}
+}
+
+func (s *webhookService) enqueue(ctx context.Context, event string, data interface{}) error {
+	endpoints, err := s.repository.GetActiveEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		if !subscribed(endpoint, event) {
+			continue
+		}
+
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		payload, err := json.Marshal(Envelope{ID: hex.EncodeToString(id), Event: event, CreatedAt: now, Data: data})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.repository.SaveDelivery(ctx, model.WebhookDelivery{
+			WebhookEndpointID: endpoint.ID,
+			Event:             event,
+			Payload:           string(payload),
+			Status:            StatusPending,
+			NextAttemptAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountPending is the delivery backlog, including deliveries waiting for a retry
+func (s *webhookService) CountPending(ctx context.Context) (int64, error) {
+	return s.repository.CountPendingDeliveries(ctx)
+}
+
+// ProcessDue sends the deliveries whose next attempt is due and reschedules the failed ones
+func (s *webhookService) ProcessDue(ctx context.Context) (int, error) {
+	deliveries, err := s.repository.GetDueDeliveries(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return sent, nil
+		}
+		delivery = s.deliver(ctx, delivery)
+		if ctx.Err() != nil {
+			// cut off by shutdown, the delivery stays due and is sent again after the restart
+			return sent, nil
+		}
+		_, err = s.repository.SaveDelivery(ctx, delivery)
+		if err != nil {
+			return sent, err
+		}
+		if delivery.Status == StatusSucceeded {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *webhookService) deliver(ctx context.Context, delivery model.WebhookDelivery) model.WebhookDelivery {
+	delivery.Attempts++
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	// endpoints created before the url check existed are checked here too
+	err := validateURL(delivery.WebhookEndpoint.URL)
+	var request *http.Request
+	if err == nil {
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, delivery.WebhookEndpoint.URL, bytes.NewBufferString(delivery.Payload))
+	}
+	if err == nil {
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("User-Agent", "nurul-iman-webhook/1.0")
+		request.Header.Set("X-Webhook-Event", delivery.Event)
+		request.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+		request.Header.Set("X-Webhook-Timestamp", timestamp)
+		request.Header.Set("X-Webhook-Signature", "sha256="+Sign(delivery.WebhookEndpoint.Secret, timestamp, []byte(delivery.Payload)))
+
+		var response *http.Response
+		response, err = s.client.Do(request)
+		if err == nil {
+			body, _ := io.ReadAll(io.LimitReader(response.Body, maxLogLength))
+			response.Body.Close()
+
+			delivery.ResponseStatus = response.StatusCode
+			delivery.ResponseBody = string(body)
+			if response.StatusCode < 200 || response.StatusCode > 299 {
+				err = fmt.Errorf("endpoint answered %d", response.StatusCode)
+			}
+		}
+	}
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = StatusSucceeded
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+		return delivery
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= MaxAttempts {
+		delivery.Status = StatusFailed
+		return delivery
+	}
+	delivery.NextAttemptAt = time.Now().Add(Backoff(delivery.Attempts))
+	return delivery
+}
+
+// Sign is the hex HMAC-SHA256 of "timestamp.body", receivers recompute it with their secret
+// and should reject old timestamps to stop replays
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff doubles the wait after every failed attempt: 30s, 1m, 2m ... capped at 6h
+func Backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return wait
+}
+
+func subscribed(endpoint model.WebhookEndpoint, event string) bool {
+	for _, item := range strings.Split(endpoint.Events, ",") {
+		if item == event {
+			return true
+		}
+	}
+	return false
+}
+
+func knownEvent(event string) bool {
+	for _, item := range Events {
+		if item == event {
+			return true
+		}
+	}
+	return false
+}
+
+func StartDeliveryWorker(jobs *worker.Group, service WebhookService, interval time.Duration) {
+	jobs.Every(interval, false, func(ctx context.Context) {
+		sent, err := service.ProcessDue(ctx)
+		if err != nil {
+			log.Printf("webhook delivery failed: %v", err)
+			return
+		}
+		if sent > 0 {
+			log.Printf("webhook delivered %d events", sent)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{
+			name:      "event payload",
+			secret:    "whsec_test",
+			timestamp: "1760000000",
+			body:      `{"event":"announcement.published"}`,
+			want:      "3922afcc229e88d4613b8d9830e174b012f6a9bc7ba016afa86b912bb92f735f",
+		},
+		{
+			name:      "empty secret and body",
+			timestamp: "1",
+			want:      "c026d3e9b78f258f71e236d9191954480d6b609fd93524964f0643741eaf81b3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Sign(test.secret, test.timestamp, []byte(test.body))
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	// the timestamp is signed too, a replay with a fresh timestamp does not verify
+	if Sign("whsec_test", "1760000000", []byte("{}")) == Sign("whsec_test", "1760000001", []byte("{}")) {
+		t.Error("timestamp is not part of the signature")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{1000, 6 * time.Hour},
+	}
+
+	for _, test := range tests {
+		if got := Backoff(test.attempts); got != test.want {
+			t.Errorf("Backoff(%d) = %v, want %v", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://example.org/hooks", false},
+		{"http://example.org:8080/hooks", false},
+		{"ftp://example.org/hooks", true},
+		{"file:///etc/passwd", true},
+		{"https:///hooks", true},
+		{"example.org/hooks", true},
+		{"://broken", true},
+	}
+
+	for _, test := range tests {
+		err := validateURL(test.url)
+		if (err != nil) != test.wantErr {
+			t.Errorf("validateURL(%q) error %v, want error %v", test.url, err, test.wantErr)
+		}
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, test := range tests {
+		if got := publicIP(net.ParseIP(test.ip)); got != test.want {
+			t.Errorf("publicIP(%s) = %v, want %v", test.ip, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var errUnsafeTarget = errors.New("webhook target is not a public address")
+
+// newClient only talks to public addresses. The check runs on the resolved ip right before connecting,
+// so a hostname that resolves to the metadata service or the database host is refused, redirects included
+func newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: refusePrivate}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// no proxy, it would connect on our behalf and skip the check
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConnsPerHost: 2,
+		},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return errors.New("too many redirects")
+			}
+			return validateURL(request.URL.String())
+		},
+	}
+}
+
+// validateURL accepts absolute http and https urls only
+func validateURL(raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("webhook url must be http or https, got %q", target.Scheme)
+	}
+	if target.Hostname() == "" {
+		return errors.New("webhook url has no host")
+	}
+	return nil
+}
+
+func refusePrivate(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return errUnsafeTarget
+	}
+	return nil
+}
+
+// sharedAddressSpace is carrier-grade NAT, some clouds use it for internal services
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsUnspecified() && !ip.IsMulticast() && !sharedAddressSpace.Contains(ip)
+}
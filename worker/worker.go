@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Group runs the background jobs of the server. Stop cancels them and waits for a run in progress,
+// so a deploy never cuts an outbox or purge pass in half
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Every runs job once per interval until the group stops, the first run starts right away when immediately is set.
+// The context passed to job is cancelled when the group stops
+func (g *Group) Every(interval time.Duration, immediately bool, job func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		if immediately {
+			job(g.ctx)
+		}
+		for {
+			select {
+			case <-g.ctx.Done():
+				return
+			case <-ticker.C:
+				job(g.ctx)
+			}
+		}
+	}()
+}
+
+// Stop waits until every job has returned, or gives up when ctx is done
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}